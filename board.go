@@ -1,31 +1,44 @@
 package main
 
 import (
-	"encoding/gob"
-	"fmt"
-	"github.com/tawesoft/golib/v2/dialog"
+	"errors"
+	"io/fs"
 	"math"
 	"math/rand"
 	"os"
 	"path/filepath"
-	"strings"
 	"time"
 )
 
 // SudokuBoard is sudoku interface
 type SudokuBoard interface {
-	RevealRandom()      // Reveal random box
-	Enter(val int) bool // Check if the val is the same as in the Board
-	IsComplete() bool   // Check if the Board is complete
-	Print()             // Print the Board
-	Move(col, row int)  // Move the cursor if possible
-	Rules() string      // Return rules of sudoku
-	Display() bool      // Return whether there were any changes since last call of Print
-	Undo()              // Undoes previous move
-	Redo()              // Cancels last undo call
-	SaveGame() error    // Save game state
-	TimePass(sec int)   // Decrement left play time by sec seconds
-	TimeEnd() bool      // Return whether the game time has ended
+	RevealRandom()                          // Reveal random box
+	Enter(val int) bool                     // Enter val at current position and return whether the board changed
+	Note(val int, corner bool) bool         // Toggle pencil mark at current position
+	Erase() bool                            // Clear value and pencil marks at current position
+	AutoNotes()                             // Fill empty boxes with all candidates as pencil marks
+	SetAutoCleanup(on bool)                 // Remove entered numbers from pencil marks of peers
+	SetCheckMode(mode int)                  // Choose how mistakes are shown
+	Check(show bool) []Vector2              // Return wrong numbers with time penalty, show them if show
+	IsComplete() bool                       // Check if the Board is complete
+	HideCursor()                            // Hide cursor when the game ends
+	IsFilled() bool                         // Check if every box has a number
+	Print(r Renderer)                       // Print the Board with renderer
+	View() BoardView                        // Return view model of the Board
+	Move(col, row int)                      // Move the cursor if possible
+	Place(pos Vector2) bool                 // Move the cursor to the box at pos if there is one
+	Cursor() Vector2                        // Return cursor position
+	Rules() string                          // Return rules of sudoku
+	Display() bool                          // Return whether there were any changes since last call of Print
+	Undo()                                  // Undoes previous move
+	Redo()                                  // Cancels last undo call
+	SaveGame(name string) (string, error)   // Save game state, in file of the board type if name is empty, return its path
+	SaveSecure(name string) (string, error) // Save game state without the answers, return path of the file
+	Restart()                               // Clear everything entered and start the clock again
+	Export(content, format int) string      // Return board content as text
+	Clock() *GameClock                      // Return clock of the play time
+	Tick()                                  // Mark the board changed when shown time changes
+	TimeEnd() bool                          // Return whether the game time has ended
 }
 
 // Vector2 to store two dimensional vector values
@@ -36,9 +49,12 @@ type Vector2 struct {
 
 // Change to store move made in Basic and Diagonal sudoku
 type Change struct {
-	Pos    Vector2 // position of change
-	OldVal int     // value before move
-	NewVal int     // value after move
+	Pos      Vector2   // position of change
+	OldVal   int       // value before move
+	NewVal   int       // value after move
+	OldNotes NoteMarks // pencil marks before move
+	NewNotes NoteMarks // pencil marks after move
+	Chained  bool      // undone and redone together with the previous move
 }
 
 // DoubleChange to store move made in TwoDoku
@@ -49,15 +65,25 @@ type DoubleChange struct {
 
 // BasicSudoku struct to implement basic sudoku board
 type BasicSudoku struct {
-	BoardShow     [][]int  // board to show to user
-	Board         [][]int  // board with answers
-	Size          int      // size of the board
-	NonetSize     Vector2  // size of one nonet(width and height)
-	Changed       bool     // changed since last Print function call
-	CursorPos     Vector2  // current player position
-	Actions       []Change // store player moves
-	CurrentAction int      // current move
-	TimeLeft      int      // time left on timer
+	BoardShow     [][]int       // board to show to user
+	Givens        [][]bool      // boxes given at the start of the game, can not be changed
+	Board         [][]int       // board with answers
+	Size          int           // size of the board
+	NonetSize     Vector2       // size of one nonet(width and height)
+	Changed       bool          // changed since last Print function call
+	CursorPos     Vector2       // current player position
+	Notes         [][]NoteMarks // pencil marks of every box, nil if there are none
+	AutoCleanup   bool          // entered numbers are removed from pencil marks of peers
+	CheckMode     int           // how mistakes are shown, answers, rules or blind
+	Seed          int64         // random seed the puzzle was generated with, 0 if it was not generated
+	Actions       []Change      // store player moves
+	CurrentAction int           // current move
+	Time          GameClock     // play time
+	TimeLeft      int           // seconds left on timer in saves of version 1, -1 if there was no timer
+	Elapsed       int           // seconds played in saves of version 1
+	shownTime     int           // seconds played when the board was printed
+	showWrong     bool          // wrong numbers are shown after check until the next move
+	rng           *rand.Rand    // source of random reveals, made from Seed if the board was loaded
 }
 
 // DiagonalSudoku struct to implement diagonal sudoku board
@@ -103,6 +129,17 @@ func findClosestFactors(x int) (int, int) {
 	return a, x / a
 }
 
+// calculate nonet size(height and width) for the board size
+func nonetSize(size int) Vector2 {
+	tmpSize := math.Sqrt(float64(size))
+	if tmpSize == math.Trunc(tmpSize) { // if square root is integer - nonet is square
+		return Vector2{int(tmpSize), int(tmpSize)}
+	}
+	// else - calculate closest factors
+	w, h := findClosestFactors(size)
+	return Vector2{w, h}
+}
+
 // PreInit include same init steps for all boards
 func (s *BasicSudoku) PreInit(size int, playTime int) {
 	s.Changed = true
@@ -120,29 +157,39 @@ func (s *BasicSudoku) PreInit(size int, playTime int) {
 	s.Size = size
 
 	// calculate nonet size
-	tmpSize := math.Sqrt(float64(size))
-	if tmpSize == math.Trunc(tmpSize) { // if square root is integer - nonet is square
-		s.NonetSize.Xpos = int(tmpSize)
-		s.NonetSize.Ypos = int(tmpSize)
-	} else { // else - calculate closest factors
-		w, h := findClosestFactors(size)
-		s.NonetSize.Xpos = w
-		s.NonetSize.Ypos = h
-	}
+	s.NonetSize = nonetSize(size)
 
 	s.CursorPos = Vector2{0, 0}
+	s.Givens = nil
+	s.Notes = nil
 	s.Actions = nil
 	s.CurrentAction = 0
-	s.TimeLeft = playTime
+	s.Time = newClock(playTime)
+}
+
+// markGivens to mark boxes with numbers in grid as given
+func (s *BasicSudoku) markGivens(grid [][]int) {
+	s.Givens = make([][]bool, s.Size)
+	for i := range s.Givens {
+		s.Givens[i] = make([]bool, s.Size)
+		for j := range s.Givens[i] {
+			s.Givens[i][j] = grid[i][j] != 0
+		}
+	}
 }
 
-// Init to init the board
-func (s *BasicSudoku) Init(size, difficulty, playTime int) {
+// isGiven returns whether the box at pos was given at the start of the game
+func (s *BasicSudoku) isGiven(pos Vector2) bool {
+	return s.Givens != nil && s.Givens[pos.Xpos][pos.Ypos]
+}
+
+// Init to init the board with numbers chosen by rng
+func (s *BasicSudoku) Init(size, difficulty, playTime int, rng *rand.Rand) {
 	// preinit call
 	s.PreInit(size, playTime)
 
 	// fill sudoku
-	s.FillSudoku(0)
+	s.FillSudoku(0, rng)
 	// copy main board to show board
 	for i := 0; i < s.Size; i++ {
 		for j := 0; j < s.Size; j++ {
@@ -151,14 +198,16 @@ func (s *BasicSudoku) Init(size, difficulty, playTime int) {
 	}
 
 	// empty grid
-	s.EmptyGrid(difficulty)
+	s.EmptyGrid(difficulty, rng)
+	s.markGivens(s.BoardShow)
+	s.rng = rng
 }
-func (s *DiagonalSudoku) Init(size, difficulty, playTime int) {
+func (s *DiagonalSudoku) Init(size, difficulty, playTime int, rng *rand.Rand) {
 	// preinit call
 	s.PreInit(size, playTime)
 
 	// fill sudoku
-	s.FillSudoku(0)
+	s.FillSudoku(0, rng)
 	// copy main board to show board
 	for i := 0; i < s.Size; i++ {
 		for j := 0; j < s.Size; j++ {
@@ -167,25 +216,28 @@ func (s *DiagonalSudoku) Init(size, difficulty, playTime int) {
 	}
 
 	// empty grid
-	s.EmptyGrid(difficulty)
+	s.EmptyGrid(difficulty, rng)
+	s.markGivens(s.BoardShow)
+	s.rng = rng
 }
-func (s *TwoDoku) Init(size, difficulty, playTime int) {
+func (s *TwoDoku) Init(size, difficulty, playTime int, rng *rand.Rand) {
 	// preinit call
 	s.BoardMain.PreInit(size, playTime)
 	s.BoardAdd.PreInit(size, playTime)
 	s.BoardAdd.CursorPos = Vector2{-1, -1} // cursor is -1 -1 if not in scope of the board
 	s.Actions = nil
 	s.CurrentAction = 0
+	s.BoardMain.rng = rng
 
 	// last steps to init board
 	FinishInit := func(board BasicSudoku) {
-		board.FillSudoku(0)
+		board.FillSudoku(0, rng)
 		for i := 0; i < size; i++ {
 			for j := 0; j < size; j++ {
 				board.BoardShow[i][j] = board.Board[i][j]
 			}
 		}
-		board.EmptyGrid(difficulty)
+		board.EmptyGrid(difficulty, rng)
 	}
 	FinishInit(s.BoardMain)
 	// copy main board's 9-th nonet to additional board's 1-st nonet
@@ -201,67 +253,80 @@ func (s *TwoDoku) Init(size, difficulty, playTime int) {
 			s.BoardAdd.BoardShow[i][j] = s.BoardMain.BoardShow[i+6][j+6]
 		}
 	}
+	s.BoardMain.markGivens(s.BoardMain.BoardShow)
+	s.BoardAdd.markGivens(s.BoardAdd.BoardShow)
 }
 
-// Enter to enter value at current position and return the success bool
+// Enter to enter value at current position and return whether the box changed
 func (s *BasicSudoku) Enter(val int) bool {
-	// if val is invalid or value is not changing - return
-	if val > s.Size || s.Board[s.CursorPos.Xpos][s.CursorPos.Ypos] == s.BoardShow[s.CursorPos.Xpos][s.CursorPos.Ypos] || val == s.BoardShow[s.CursorPos.Xpos][s.CursorPos.Ypos] {
-		return false
-	}
+	return s.enter(val, false)
+}
+func (s *DiagonalSudoku) Enter(val int) bool {
+	return s.enter(val, true)
+}
 
-	// clear next moves if there is any
-	currAct := s.CurrentAction
-	for currAct != len(s.Actions) {
-		s.Actions = s.Actions[0:currAct]
+// enter value at current position, diagonal boxes are peers in diagonal sudoku
+func (s *BasicSudoku) enter(val int, diagonal bool) bool {
+	// if val is invalid, box is given or value is not changing - return
+	if val > s.Size || s.isGiven(s.CursorPos) || val == s.BoardShow[s.CursorPos.Xpos][s.CursorPos.Ypos] {
+		return false
 	}
 
-	// add new move
-	s.Actions = append(s.Actions, Change{s.CursorPos, s.BoardShow[s.CursorPos.Xpos][s.CursorPos.Ypos], val})
-	s.CurrentAction = len(s.Actions)
-	s.Changed = true
+	// add new move, pencil marks stay under the value
+	notes := s.notesAt(s.CursorPos)
+	s.addAction(Change{s.CursorPos, s.BoardShow[s.CursorPos.Xpos][s.CursorPos.Ypos], val, notes, notes, false})
 
-	// check if new value is correct
-	success := true
-	if s.Board[s.CursorPos.Xpos][s.CursorPos.Ypos] != val {
-		success = false
-	}
 	s.BoardShow[s.CursorPos.Xpos][s.CursorPos.Ypos] = val
-	return success
+	if s.AutoCleanup {
+		s.cleanNotes(s.CursorPos, val, diagonal)
+	}
+	return true
 }
 func (s *TwoDoku) Enter(val int) bool {
+	mainBefore, addBefore := s.BoardMain.CurrentAction, s.BoardAdd.CurrentAction
 	ret := false
-	// enter value to the respective board
+	// enter value to the respective board, boxes of the shared nonet change in both
 	if s.BoardMain.CursorPos.Xpos != -1 {
 		ret = s.BoardMain.Enter(val)
 	}
 	if s.BoardAdd.CursorPos.Xpos != -1 {
-		ret = s.BoardAdd.Enter(val)
+		ret = s.BoardAdd.Enter(val) || ret
 	}
 
 	// if there is a change - update move sequence
-	if s.Display() {
-		// clear next moves
-		currAct := s.CurrentAction
-		for currAct != len(s.Actions) {
-			s.Actions = s.Actions[0:currAct]
-		}
-		// if main 9-th and additional 1-st nonets - there is 1 move in each board
-		if s.BoardMain.Changed && s.BoardAdd.Changed {
-			s.Actions = append(s.Actions, DoubleChange{true, true})
-			s.Actions = append(s.Actions, DoubleChange{false, true})
-		} else if s.BoardMain.Changed {
-			s.Actions = append(s.Actions, DoubleChange{true, false})
-		} else if s.BoardAdd.Changed {
-			s.Actions = append(s.Actions, DoubleChange{false, false})
-		}
-		s.CurrentAction = len(s.Actions)
+	s.syncShared(mainBefore, addBefore)
+	s.record(mainBefore, addBefore)
+	return ret
+}
+
+// Erase to clear value and marks at current position and return whether the box changed
+func (s *BasicSudoku) Erase() bool {
+	pos := s.CursorPos
+	// givens stay, empty boxes have nothing to clear
+	if pos.Xpos == -1 || s.isGiven(pos) || (s.BoardShow[pos.Xpos][pos.Ypos] == 0 && s.notesAt(pos).Empty()) {
+		return false
+	}
+	s.addAction(Change{pos, s.BoardShow[pos.Xpos][pos.Ypos], 0, s.notesAt(pos), NoteMarks{}, false})
+	s.BoardShow[pos.Xpos][pos.Ypos] = 0
+	s.setNotes(pos, NoteMarks{})
+	return true
+}
+func (s *TwoDoku) Erase() bool {
+	mainBefore, addBefore := s.BoardMain.CurrentAction, s.BoardAdd.CurrentAction
+	ret := false
+	// boxes of the shared nonet are cleared in both boards
+	if s.BoardMain.CursorPos.Xpos != -1 {
+		ret = s.BoardMain.Erase()
+	}
+	if s.BoardAdd.CursorPos.Xpos != -1 {
+		ret = s.BoardAdd.Erase()
 	}
+	s.record(mainBefore, addBefore)
 	return ret
 }
 
-// FillSudoku to fill main board with numbers
-func (s *BasicSudoku) FillSudoku(position int) bool {
+// FillSudoku to fill main board with numbers in order shuffled by rng
+func (s *BasicSudoku) FillSudoku(position int, rng *rand.Rand) bool {
 	// recursive stop when reached last box
 	if position == s.Size*s.Size {
 		return true
@@ -270,11 +335,11 @@ func (s *BasicSudoku) FillSudoku(position int) bool {
 	y := position % s.Size
 	// skip filled boxes
 	if s.Board[x][y] != 0 {
-		return s.FillSudoku(position + 1)
+		return s.FillSudoku(position+1, rng)
 	}
 
 	// calculate available nums for current box
-	available := s.AvailableNum(position, s.Board)
+	available := s.AvailableNum(position, s.Board, rng)
 	lenAvailable := len(available)
 	if lenAvailable == 0 {
 		return false
@@ -285,7 +350,7 @@ func (s *BasicSudoku) FillSudoku(position int) bool {
 		// assign new value
 		s.Board[x][y] = available[i]
 		// if solution is found
-		if s.FillSudoku(position + 1) {
+		if s.FillSudoku(position+1, rng) {
 			return true
 		}
 	}
@@ -293,7 +358,7 @@ func (s *BasicSudoku) FillSudoku(position int) bool {
 	s.Board[x][y] = 0
 	return false
 }
-func (s *DiagonalSudoku) FillSudoku(position int) bool {
+func (s *DiagonalSudoku) FillSudoku(position int, rng *rand.Rand) bool {
 	// recursive stop when reached last box
 	if position == s.Size*s.Size {
 		return true
@@ -302,11 +367,11 @@ func (s *DiagonalSudoku) FillSudoku(position int) bool {
 	y := position % s.Size
 	// skip filled boxes
 	if s.Board[x][y] != 0 {
-		return s.FillSudoku(position + 1)
+		return s.FillSudoku(position+1, rng)
 	}
 
 	// calculate available nums for current box
-	available := s.AvailableNum(position, s.Board)
+	available := s.AvailableNum(position, s.Board, rng)
 	lenAvailable := len(available)
 	if lenAvailable == 0 {
 		return false
@@ -317,7 +382,7 @@ func (s *DiagonalSudoku) FillSudoku(position int) bool {
 		// assign new value
 		s.Board[x][y] = available[i]
 		// if solution is found
-		if s.FillSudoku(position + 1) {
+		if s.FillSudoku(position+1, rng) {
 			return true
 		}
 	}
@@ -326,8 +391,8 @@ func (s *DiagonalSudoku) FillSudoku(position int) bool {
 	return false
 }
 
-// AvailableNum to calculate all possible numbers for current box
-func (s *BasicSudoku) AvailableNum(position int, board [][]int) []int {
+// AvailableNum to calculate all possible numbers for current box, shuffled by rng if it is not nil
+func (s *BasicSudoku) AvailableNum(position int, board [][]int, rng *rand.Rand) []int {
 	x := position / s.Size
 	y := position % s.Size
 
@@ -378,15 +443,16 @@ func (s *BasicSudoku) AvailableNum(position int, board [][]int) []int {
 		}
 	}
 
-	// randomly shuffle numbers
-	for i := range available {
-		j := rand.Intn(i + 1)
-		available[i], available[j] = available[j], available[i]
+	// randomly shuffle numbers, unless only the set matters
+	if rng != nil {
+		rng.Shuffle(len(available), func(i, j int) {
+			available[i], available[j] = available[j], available[i]
+		})
 	}
 
 	return available
 }
-func (s *DiagonalSudoku) AvailableNum(position int, board [][]int) []int {
+func (s *DiagonalSudoku) AvailableNum(position int, board [][]int, rng *rand.Rand) []int {
 	x := position / s.Size
 	y := position % s.Size
 
@@ -459,20 +525,18 @@ func (s *DiagonalSudoku) AvailableNum(position int, board [][]int) []int {
 		}
 	}
 
-	// randomly shuffle numbers
-	for i := range available {
-		j := rand.Intn(i + 1)
-		available[i], available[j] = available[j], available[i]
+	// randomly shuffle numbers, unless only the set matters
+	if rng != nil {
+		rng.Shuffle(len(available), func(i, j int) {
+			available[i], available[j] = available[j], available[i]
+		})
 	}
 
 	return available
 }
 
-// number of solutions found
-var solutions int
-
-// EmptyGrid to empty show board
-func (s *BasicSudoku) EmptyGrid(difficulty int) {
+// EmptyGrid to empty show board in boxes chosen by rng
+func (s *BasicSudoku) EmptyGrid(difficulty int, rng *rand.Rand) {
 	// calculate how much boxes to empty in percentages
 	emptyFinal := (difficulty + 1) * 25
 	// copy of grid for removal
@@ -484,21 +548,17 @@ func (s *BasicSudoku) EmptyGrid(difficulty int) {
 
 	for !finished {
 		// calculate random non empty box
-		row, col := rand.Intn(s.Size), rand.Intn(s.Size)
+		row, col := rng.Intn(s.Size), rng.Intn(s.Size)
 		for s.BoardShow[row][col] == 0 {
-			row, col = rand.Intn(s.Size), rand.Intn(s.Size)
+			row, col = rng.Intn(s.Size), rng.Intn(s.Size)
 		}
 
 		// copy current grid and empty new position box
 		copyGrid.Copy(s)
-		solutions = 0
 		copyGrid.BoardShow[row][col] = 0
 
-		// calculate number of solutions
-		copyGrid.SolveGrid(0)
-
 		// if there is only 1 solution - accept changes
-		if solutions == 1 {
+		if copyGrid.SolveGrid(0) == 1 {
 			s.BoardShow[row][col] = 0
 			empty++
 		}
@@ -511,7 +571,7 @@ func (s *BasicSudoku) EmptyGrid(difficulty int) {
 	}
 
 }
-func (s *DiagonalSudoku) EmptyGrid(difficulty int) {
+func (s *DiagonalSudoku) EmptyGrid(difficulty int, rng *rand.Rand) {
 	// calculate how much boxes to empty in percentages
 	emptyFinal := (difficulty + 1) * 25
 	// copy of grid for removal
@@ -523,21 +583,17 @@ func (s *DiagonalSudoku) EmptyGrid(difficulty int) {
 
 	for !finished {
 		// calculate random non empty box
-		row, col := rand.Intn(s.Size), rand.Intn(s.Size)
+		row, col := rng.Intn(s.Size), rng.Intn(s.Size)
 		for s.BoardShow[row][col] == 0 {
-			row, col = rand.Intn(s.Size), rand.Intn(s.Size)
+			row, col = rng.Intn(s.Size), rng.Intn(s.Size)
 		}
 
 		// copy current grid and empty new position box
 		copyGrid.Copy(s)
-		solutions = 0
 		copyGrid.BoardShow[row][col] = 0
 
-		// calculate number of solutions
-		copyGrid.SolveGrid(0)
-
 		// if there is only 1 solution - accept changes
-		if solutions == 1 {
+		if copyGrid.SolveGrid(0) == 1 {
 			s.BoardShow[row][col] = 0
 			empty++
 		}
@@ -551,12 +607,11 @@ func (s *DiagonalSudoku) EmptyGrid(difficulty int) {
 
 }
 
-// SolveGrid to calculate number of solutions for current board
-func (s *BasicSudoku) SolveGrid(position int) {
-	// if reached end - increment solutions and return
+// SolveGrid to return number of solutions for current board
+func (s *BasicSudoku) SolveGrid(position int) int {
+	// if reached end - one solution is found
 	if position == s.Size*s.Size {
-		solutions++
-		return
+		return 1
 	}
 
 	x := position / s.Size
@@ -564,28 +619,27 @@ func (s *BasicSudoku) SolveGrid(position int) {
 
 	// skip non-empty boxes
 	if s.BoardShow[x][y] != 0 {
-		s.SolveGrid(position + 1)
-		return
+		return s.SolveGrid(position + 1)
 	}
 
 	// calculate available nums for current box and loop through them
-	available := s.AvailableNum(position, s.BoardShow)
+	solutions := 0
+	available := s.AvailableNum(position, s.BoardShow, nil)
 	lenAvailable := len(available)
 	for i := 0; i < lenAvailable; i++ {
 		s.BoardShow[x][y] = available[i]
 		// recursively find all the solutions
-		s.SolveGrid(position + 1)
+		solutions += s.SolveGrid(position + 1)
 	}
 
 	// reset the empty box and return
 	s.BoardShow[x][y] = 0
-	return
+	return solutions
 }
-func (s *DiagonalSudoku) SolveGrid(position int) {
-	// if reached end - increment solutions and return
+func (s *DiagonalSudoku) SolveGrid(position int) int {
+	// if reached end - one solution is found
 	if position == s.Size*s.Size {
-		solutions++
-		return
+		return 1
 	}
 
 	x := position / s.Size
@@ -593,267 +647,22 @@ func (s *DiagonalSudoku) SolveGrid(position int) {
 
 	// skip non-empty boxes
 	if s.BoardShow[x][y] != 0 {
-		s.SolveGrid(position + 1)
-		return
+		return s.SolveGrid(position + 1)
 	}
 
 	// calculate available nums for current box and loop through them
-	available := s.AvailableNum(position, s.BoardShow)
+	solutions := 0
+	available := s.AvailableNum(position, s.BoardShow, nil)
 	lenAvailable := len(available)
 	for i := 0; i < lenAvailable; i++ {
 		s.BoardShow[x][y] = available[i]
 		// recursively find all the solutions
-		s.SolveGrid(position + 1)
+		solutions += s.SolveGrid(position + 1)
 	}
 
 	// reset the empty box and return
 	s.BoardShow[x][y] = 0
-	return
-}
-
-// SudokuPrintManual to print common parts of sudoku manual for every board
-func SudokuPrintManual() {
-	blueFont.Println("Move with arrows, enter with numbers 1-9(and A-C, depending on Board Size)")
-	greenFont.Print("Green")
-	blueFont.Println(" - solved")
-	redFont.Print("Red")
-	blueFont.Println(" - incorrect")
-	purpleFont.Print("Purple")
-	blueFont.Println(" - cursor")
-}
-
-// Print to output to console the show board as well as timer and instructions
-func (s *BasicSudoku) Print() {
-	if !s.Changed {
-		return
-	}
-	SudokuPrintManual()
-	s.Changed = false
-	printFont := fmt.Printf
-	// loop through show board
-	for i, line := range s.BoardShow {
-		// print horizontal borders for nonets
-		if i%s.NonetSize.Xpos == 0 {
-			// mid-board borders
-			if i > 0 {
-				blueFont.Print(strings.Repeat("|"+strings.Repeat("_", s.NonetSize.Ypos*2+1), s.Size/s.NonetSize.Ypos))
-				blueFont.Println("|")
-			} else { // first line border
-				blueFont.Print(strings.Repeat("_"+strings.Repeat("_", s.NonetSize.Ypos*2+1), s.Size/s.NonetSize.Ypos))
-				blueFont.Println("_")
-			}
-		}
-		for j, element := range line {
-			// print vertical borders
-			if j%s.NonetSize.Ypos == 0 {
-				blueFont.Print("| ")
-			}
-			// incorrect element
-			if element != 0 && s.Board[i][j] != element {
-				printFont = redFont.Printf
-			} else if s.CursorPos.Xpos == i && s.CursorPos.Ypos == j { // element where cursor is located
-				printFont = purpleFont.Printf
-			} else if element != 0 { // correct element
-				printFont = greenFont.Printf
-			} else { // empty element
-				printFont = fmt.Printf
-			}
-			// handle numbers over 9
-			if element > 9 {
-				// transform to letter format
-				_, _ = printFont("%c ", 'A'+(element-10))
-			} else {
-				_, _ = printFont("%d ", element)
-			}
-		}
-		blueFont.Print("|")
-		fmt.Println()
-	}
-	// print last horizontal border
-	blueFont.Print(strings.Repeat("|"+strings.Repeat("_", s.NonetSize.Ypos*2+1), s.Size/s.NonetSize.Ypos))
-	blueFont.Println("|")
-	// output time left if timer exist
-	if s.TimeLeft != -1 {
-		minutes := s.TimeLeft / 60
-		seconds := s.TimeLeft % 60
-		_, _ = greenFont.Printf("Time remaining: %02d:%02d\n", minutes, seconds)
-	}
-}
-func (s *DiagonalSudoku) Print() {
-	if !s.Changed {
-		return
-	}
-	SudokuPrintManual()
-	diagonalFont.Print("Yellow")
-	blueFont.Println(" - correct diagonal")
-
-	s.Changed = false
-	printFont := fmt.Printf
-	// loop through show board
-	for i, line := range s.BoardShow {
-		// print horizontal borders for nonets
-		if i%s.NonetSize.Xpos == 0 {
-			// mid-board borders
-			if i > 0 {
-				blueFont.Print(strings.Repeat("|"+strings.Repeat("_", s.NonetSize.Ypos*2+1), s.Size/s.NonetSize.Ypos))
-				blueFont.Println("|")
-			} else { // first line border
-				blueFont.Print(strings.Repeat("_"+strings.Repeat("_", s.NonetSize.Ypos*2+1), s.Size/s.NonetSize.Ypos))
-				blueFont.Println("_")
-			}
-		}
-		for j, element := range line {
-			// print vertical borders
-			if j%s.NonetSize.Ypos == 0 {
-				blueFont.Print("| ")
-			}
-			// incorrect element
-			if element != 0 && s.Board[i][j] != element {
-				printFont = redFont.Printf
-			} else if s.CursorPos.Xpos == i && s.CursorPos.Ypos == j { // element where cursor is located
-				printFont = purpleFont.Printf
-			} else if (i == j || i == s.Size-j-1) && element != 0 { // diagonal element
-				printFont = diagonalFont.Printf
-			} else if element != 0 { // correct element
-				printFont = greenFont.Printf
-			} else { // empty element
-				printFont = fmt.Printf
-			}
-			// handle numbers over 9
-			if element > 9 {
-				// transform to letter format
-				_, _ = printFont("%c ", 'A'+(element-10))
-			} else {
-				_, _ = printFont("%d ", element)
-			}
-		}
-		blueFont.Print("|")
-		fmt.Println()
-	}
-	// print last horizontal border
-	blueFont.Print(strings.Repeat("|"+strings.Repeat("_", s.NonetSize.Ypos*2+1), s.Size/s.NonetSize.Ypos))
-	blueFont.Println("|")
-	// output time left if timer exist
-	if s.TimeLeft != -1 {
-		minutes := s.TimeLeft / 60
-		seconds := s.TimeLeft % 60
-		greenFont.Printf("Time remaining: %02d:%02d\n", minutes, seconds)
-	}
-}
-func (s *TwoDoku) Print() {
-	if !s.BoardMain.Changed && !s.BoardAdd.Changed {
-		return
-	}
-	SudokuPrintManual()
-	s.BoardMain.Changed = false
-	s.BoardAdd.Changed = false
-	printFont := fmt.Printf
-	// print first 6 rows of main board
-	for i := 0; i < 6; i++ {
-		if i%3 == 0 {
-			if i > 0 {
-				blueFont.Print(strings.Repeat("|_______", 3))
-				blueFont.Println("|")
-			} else {
-				blueFont.Print(strings.Repeat("________", 3))
-				blueFont.Println("_")
-			}
-		}
-		for j := 0; j < 9; j++ {
-			if j%3 == 0 {
-				blueFont.Print("| ")
-			}
-			element := s.BoardMain.BoardShow[i][j]
-			if element != 0 && s.BoardMain.Board[i][j] != element {
-				printFont = redFont.Printf
-			} else if s.BoardMain.CursorPos.Xpos == i && s.BoardMain.CursorPos.Ypos == j {
-				printFont = purpleFont.Printf
-			} else if element != 0 {
-				printFont = greenFont.Printf
-			} else {
-				printFont = fmt.Printf
-			}
-			_, _ = printFont("%d ", element)
-		}
-		blueFont.Print("|")
-		fmt.Println()
-	}
-	// draw borders for 5 nonets
-	blueFont.Print(strings.Repeat("|_______", 3))
-	blueFont.Print("|")
-	blueFont.Println(strings.Repeat("________", 2))
-	// print last 3 and first 3 rows of main and additional boards as 5 nonets in one line
-	for i := 6; i < 9; i++ {
-		for j := 0; j < 15; j++ {
-			var value int
-			var element int
-			if j < 9 {
-				element = s.BoardMain.BoardShow[i][j]
-				value = s.BoardMain.Board[i][j]
-			} else {
-				element = s.BoardAdd.BoardShow[i-6][j%9+3]
-				value = s.BoardAdd.Board[i-6][j%9+3]
-			}
-
-			if j%3 == 0 {
-				blueFont.Print("| ")
-			}
-
-			if element != 0 && value != element {
-				printFont = redFont.Printf
-			} else if s.BoardMain.CursorPos.Xpos == i && s.BoardMain.CursorPos.Ypos == j || s.BoardAdd.CursorPos.Xpos == i-6 && s.BoardAdd.CursorPos.Ypos == j-6 {
-				printFont = purpleFont.Printf
-			} else if element != 0 {
-				printFont = greenFont.Printf
-			} else {
-				printFont = fmt.Printf
-			}
-			_, _ = printFont("%d ", element)
-		}
-		blueFont.Print("|")
-		fmt.Println()
-	}
-	// draw borders for 5 nonets
-	blueFont.Print(strings.Repeat("|_______", 5))
-	blueFont.Println("|")
-	// print last 6 rows of additional board
-	for i := 3; i < 9; i++ {
-		if i%3 == 0 && i != 3 {
-			fmt.Print(strings.Repeat("  ", 8))
-			blueFont.Print(strings.Repeat("|_______", 3))
-			blueFont.Println("|")
-
-		}
-		fmt.Print(strings.Repeat("  ", 8))
-		for j := 0; j < 9; j++ {
-			if j%3 == 0 {
-				blueFont.Print("| ")
-			}
-			element := s.BoardAdd.BoardShow[i][j]
-			if element != 0 && s.BoardAdd.Board[i][j] != element {
-				printFont = redFont.Printf
-			} else if s.BoardAdd.CursorPos.Xpos == i && s.BoardAdd.CursorPos.Ypos == j {
-				printFont = purpleFont.Printf
-			} else if element != 0 {
-				printFont = greenFont.Printf
-			} else {
-				printFont = fmt.Printf
-			}
-			_, _ = printFont("%d ", element)
-		}
-		blueFont.Print("|")
-		fmt.Println()
-	}
-	// draw last border
-	fmt.Print(strings.Repeat("  ", 8))
-	blueFont.Print(strings.Repeat("|_______", 3))
-	blueFont.Println("|")
-	// output time left if timer exist
-	if s.BoardMain.TimeLeft != -1 {
-		minutes := s.BoardMain.TimeLeft / 60
-		seconds := s.BoardMain.TimeLeft % 60
-		greenFont.Printf("Time remaining: %02d:%02d\n", minutes, seconds)
-	}
+	return solutions
 }
 
 // Copy to copy another sudoku state
@@ -911,14 +720,22 @@ func (s *BasicSudoku) IsComplete() bool {
 			}
 		}
 	}
-	s.Changed = true
-	s.CursorPos = Vector2{-1, -1}
 	return true
 }
 func (s *TwoDoku) IsComplete() bool {
 	return s.BoardMain.IsComplete() && s.BoardAdd.IsComplete()
 }
 
+// HideCursor to draw the finished board without cursor
+func (s *BasicSudoku) HideCursor() {
+	s.CursorPos = Vector2{-1, -1}
+	s.Changed = true
+}
+func (s *TwoDoku) HideCursor() {
+	s.BoardMain.HideCursor()
+	s.BoardAdd.HideCursor()
+}
+
 // Display returns whether there is anything new to display
 func (s *BasicSudoku) Display() bool {
 	return s.Changed
@@ -930,7 +747,8 @@ func (s *TwoDoku) Display() bool {
 // Rules returns sudoku rules
 func (s *BasicSudoku) Rules() string {
 	return "Sudoku is played on a 9x9(or other sizes) grid where each row, column,\n" +
-		"and 3x3(can differ) region must contain all digits from 1 to 9(possibly up to C) without repetition.\n" +
+		"and 3x3(can differ) region must contain all digits from 1 to 9(possibly up to C)\n" +
+		"without repetition.\n" +
 		"Use logic to fill in the empty cells based on the filled cells.\n" +
 		"No guessing is allowed, and each puzzle has exactly one unique solution."
 }
@@ -951,42 +769,52 @@ func (s *TwoDoku) Rules() string {
 
 // Move to move cursor
 func (s *BasicSudoku) Move(col, row int) {
-	newPos := Vector2{s.CursorPos.Xpos + row, s.CursorPos.Ypos + col}
-	if newPos.Xpos < s.Size && newPos.Ypos < s.Size && newPos.Xpos >= 0 && newPos.Ypos >= 0 {
-		s.CursorPos.Xpos = newPos.Xpos
-		s.CursorPos.Ypos = newPos.Ypos
-		s.Changed = true
-	}
+	s.Place(Vector2{s.CursorPos.Xpos + row, s.CursorPos.Ypos + col})
 }
 func (s *TwoDoku) Move(col, row int) {
-	var currentPos Vector2
-	// calculate pos from 0 to 15 based on in which board cursor is currently
-	if s.BoardMain.CursorPos.Xpos == -1 { // second board
-		currentPos = s.BoardAdd.CursorPos
-		currentPos.Xpos += 6
-		currentPos.Ypos += 6
-	} else if s.BoardAdd.CursorPos.Xpos == -1 { // main board
-		currentPos = s.BoardMain.CursorPos
-	} else { // adjacent nonet
-		currentPos = s.BoardMain.CursorPos
-	}
-	newPos := Vector2{currentPos.Xpos + row, currentPos.Ypos + col}
-	// check for invalid new position
-	if newPos.Xpos > 14 || newPos.Ypos > 14 || newPos.Xpos < 0 || newPos.Ypos < 0 || (newPos.Xpos > 8 && newPos.Ypos < 6) || (newPos.Xpos < 6 && newPos.Ypos > 8) {
-		return
-	}
-	// assign new positions
-	s.BoardAdd.CursorPos.Xpos = newPos.Xpos - 6
-	s.BoardAdd.CursorPos.Ypos = newPos.Ypos - 6
-	s.BoardMain.CursorPos.Xpos = newPos.Xpos
-	s.BoardMain.CursorPos.Ypos = newPos.Ypos
-	if (newPos.Xpos <= 8 && newPos.Ypos < 6) || (newPos.Xpos < 6 && newPos.Ypos <= 8) {
-		s.BoardAdd.CursorPos = Vector2{-1, -1}
+	current := s.Cursor()
+	s.Place(Vector2{current.Xpos + row, current.Ypos + col})
+}
 
-	} else if (newPos.Xpos > 8 && newPos.Ypos >= 6) || (newPos.Xpos >= 6 && newPos.Ypos > 8) {
-		s.BoardMain.CursorPos = Vector2{-1, -1}
+// Place to move cursor to pos and return whether there is a box at pos
+func (s *BasicSudoku) Place(pos Vector2) bool {
+	if pos.Xpos >= s.Size || pos.Ypos >= s.Size || pos.Xpos < 0 || pos.Ypos < 0 {
+		return false
+	}
+	s.CursorPos = pos
+	s.Changed = true
+	return true
+}
+func (s *TwoDoku) Place(pos Vector2) bool {
+	// pos is from 0 to 15, additional board starts at 6 6
+	inMain := pos.Xpos >= 0 && pos.Ypos >= 0 && pos.Xpos <= 8 && pos.Ypos <= 8
+	inAdd := pos.Xpos >= 6 && pos.Ypos >= 6 && pos.Xpos <= 14 && pos.Ypos <= 14
+	if !inMain && !inAdd {
+		return false
+	}
+	// cursor is in both boards in the adjacent nonet
+	s.BoardMain.CursorPos = Vector2{-1, -1}
+	s.BoardAdd.CursorPos = Vector2{-1, -1}
+	if inMain {
+		s.BoardMain.CursorPos = pos
+	}
+	if inAdd {
+		s.BoardAdd.CursorPos = Vector2{pos.Xpos - 6, pos.Ypos - 6}
 	}
 	s.BoardMain.Changed = true
+	return true
+}
+
+// Cursor returns current cursor position
+func (s *BasicSudoku) Cursor() Vector2 {
+	return s.CursorPos
+}
+func (s *TwoDoku) Cursor() Vector2 {
+	// position from 0 to 15 like in Move
+	if s.BoardMain.CursorPos.Xpos == -1 {
+		return Vector2{s.BoardAdd.CursorPos.Xpos + 6, s.BoardAdd.CursorPos.Ypos + 6}
+	}
+	return s.BoardMain.CursorPos
 }
 
 // Undo to undo last move
@@ -997,11 +825,17 @@ func (s *BasicSudoku) Undo() {
 		return
 	}
 	s.CurrentAction = action
+	s.showWrong = false
 	prevChange := s.Actions[action]
 	change := Vector2{s.CursorPos.Xpos - prevChange.Pos.Xpos, s.CursorPos.Ypos - prevChange.Pos.Ypos}
-	// move to old position and assign old value
+	// move to old position and assign old value and marks
 	s.Move(-change.Ypos, -change.Xpos)
 	s.BoardShow[s.CursorPos.Xpos][s.CursorPos.Ypos] = prevChange.OldVal
+	s.setNotes(s.CursorPos, prevChange.OldNotes)
+	// moves made by one command are undone together
+	if prevChange.Chained {
+		s.Undo()
+	}
 }
 func (s *TwoDoku) Undo() {
 	action := s.CurrentAction - 1
@@ -1023,6 +857,8 @@ func (s *TwoDoku) Undo() {
 			s.Undo()
 		}
 	}
+	// place cursor in both boards if it is in the shared nonet
+	s.Move(0, 0)
 }
 
 // Redo to redo the last undone move
@@ -1032,12 +868,18 @@ func (s *BasicSudoku) Redo() {
 	if action == len(s.Actions) {
 		return
 	}
+	s.showWrong = false
 	prevChange := s.Actions[action]
 	change := Vector2{s.CursorPos.Xpos - prevChange.Pos.Xpos, s.CursorPos.Ypos - prevChange.Pos.Ypos}
 	s.Move(-change.Ypos, -change.Xpos)
 	s.BoardShow[s.CursorPos.Xpos][s.CursorPos.Ypos] = prevChange.NewVal
+	s.setNotes(s.CursorPos, prevChange.NewNotes)
 	// step to next move
 	s.CurrentAction++
+	// moves made by one command are redone together
+	if s.CurrentAction < len(s.Actions) && s.Actions[s.CurrentAction].Chained {
+		s.Redo()
+	}
 }
 func (s *TwoDoku) Redo() {
 	action := s.CurrentAction
@@ -1059,26 +901,48 @@ func (s *TwoDoku) Redo() {
 		s.BoardAdd.Redo()
 		s.BoardMain.CursorPos = Vector2{-1, -1}
 	}
+	// place cursor in both boards if it is in the shared nonet
+	s.Move(0, 0)
 }
 
-// ClearFiles to delete all *.sudo files in local directory
-func ClearFiles() error {
-	// get current directory
-	currentDir, err := os.Getwd()
-	if err != nil {
-		return err
+// Restart to clear numbers and marks entered by the player, moves and play time
+func (s *BasicSudoku) Restart() {
+	for i := 0; i < s.Size; i++ {
+		for j := 0; j < s.Size; j++ {
+			if !s.isGiven(Vector2{i, j}) {
+				s.BoardShow[i][j] = 0
+			}
+		}
 	}
+	s.Notes = nil
+	s.Actions = nil
+	s.CurrentAction = 0
+	s.showWrong = false
+	s.Time.Reset()
+	s.Changed = true
+}
+func (s *TwoDoku) Restart() {
+	s.BoardMain.Restart()
+	s.BoardAdd.Restart()
+	s.Actions = nil
+	s.CurrentAction = 0
+}
 
-	// find all filed
-	files, err := filepath.Glob(filepath.Join(currentDir, "*.sudo"))
+// ClearFiles to delete default saves except keep in local directory
+func ClearFiles(keep string) error {
+	// get current directory
+	currentDir, err := os.Getwd()
 	if err != nil {
 		return err
 	}
 
-	// loop and delete them
-	for _, file := range files {
-		err = os.Remove(file)
-		if err != nil {
+	// loop over default saves and delete them, named saves are left alone
+	for _, name := range defaultSaves {
+		if name == keep {
+			continue
+		}
+		err = os.Remove(filepath.Join(currentDir, name))
+		if err != nil && !errors.Is(err, fs.ErrNotExist) {
 			return err
 		}
 	}
@@ -1086,87 +950,47 @@ func ClearFiles() error {
 }
 
 // SaveGame to save game state in .sudo file
-func (s *BasicSudoku) SaveGame() error {
-	// clear directory
-	err := ClearFiles()
-	if err != nil {
-		return err
-	}
-	// create file for save
-	file, err := os.Create("basic.sudo")
-	if err != nil {
-		return err
-	}
-	defer file.Close()
-
-	// Create an encoder and send struct for encoding
-	enc := gob.NewEncoder(file)
-	err = enc.Encode(s)
-	if err != nil {
-		dialog.Info(err.Error())
-		return err
-	}
-
-	return nil
+func (s *BasicSudoku) SaveGame(name string) (string, error) {
+	return writeSave(name, "basic.sudo", saveHeader{Kind: "basic"}, s)
+}
+func (s *DiagonalSudoku) SaveGame(name string) (string, error) {
+	return writeSave(name, "diagonal.sudo", saveHeader{Kind: "diagonal"}, s)
+}
+func (s *TwoDoku) SaveGame(name string) (string, error) {
+	return writeSave(name, "two.sudo", saveHeader{Kind: "two"}, s)
 }
-func (s *DiagonalSudoku) SaveGame() error {
-	// clear directory
-	err := ClearFiles()
-	if err != nil {
-		return err
-	}
-	// create file for save
-	file, err := os.Create("diagonal.sudo")
-	if err != nil {
-		return err
-	}
-	defer file.Close()
-
-	// Create an encoder and send struct for encoding
-	enc := gob.NewEncoder(file)
-	err = enc.Encode(s)
-	if err != nil {
-		dialog.Info(err.Error())
-		return err
-	}
 
-	return nil
+// RevealRandom to fill random empty box with answer
+func (s *BasicSudoku) RevealRandom() {
+	s.revealRandom(false)
+}
+func (s *DiagonalSudoku) RevealRandom() {
+	s.revealRandom(true)
 }
-func (s *TwoDoku) SaveGame() error {
-	// clear directory
-	err := ClearFiles()
-	if err != nil {
-		return err
-	}
-	// create file for save
-	file, err := os.Create("two.sudo")
-	if err != nil {
-		return err
-	}
-	defer file.Close()
 
-	// Create an encoder and send struct for encoding
-	enc := gob.NewEncoder(file)
-	err = enc.Encode(s)
-	if err != nil {
-		dialog.Info(err.Error())
-		return err
+// random returns random source of the board, loaded board gets one from its Seed
+func (s *BasicSudoku) random() *rand.Rand {
+	if s.rng == nil {
+		seed := s.Seed
+		if seed == 0 {
+			seed = rand.Int63()
+		}
+		s.rng = rand.New(rand.NewSource(seed))
 	}
-
-	return nil
+	return s.rng
 }
 
-// RevealRandom to fill random empty box with answer
-func (s *BasicSudoku) RevealRandom() {
-	row := rand.Intn(s.Size)
-	col := rand.Intn(s.Size)
+// reveal random box, diagonal boxes are peers in diagonal sudoku
+func (s *BasicSudoku) revealRandom(diagonal bool) {
+	row := s.random().Intn(s.Size)
+	col := s.random().Intn(s.Size)
 	// function to look for the first empty box and reveal it
 	look := func(x, y int) bool {
 		for i := x; i < s.Size; i++ {
 			for j := y; j < s.Size; j++ {
 				if s.BoardShow[i][j] != s.Board[i][j] {
 					s.CursorPos = Vector2{i, j}
-					s.Enter(s.Board[i][j])
+					s.enter(s.Board[i][j], diagonal)
 					return true
 				}
 			}
@@ -1181,8 +1005,8 @@ func (s *BasicSudoku) RevealRandom() {
 	look(0, 0)
 }
 func (s *TwoDoku) RevealRandom() {
-	row := rand.Intn(9)
-	col := rand.Intn(9)
+	row := s.BoardMain.random().Intn(9)
+	col := s.BoardMain.random().Intn(9)
 	// function to look for the first empty box in both boards and reveal it
 	look := func(x, y int) bool {
 		for i := x; i < 9; i++ {
@@ -1219,28 +1043,27 @@ func (s *TwoDoku) RevealRandom() {
 	look(0, 0)
 }
 
-// TimePass to decrement seconds from play time
-func (s *BasicSudoku) TimePass(sec int) {
-	// if not -1(no timer)
-	if s.TimeLeft > 0 {
+// Clock to return clock of the play time
+func (s *BasicSudoku) Clock() *GameClock {
+	return &s.Time
+}
+func (s *TwoDoku) Clock() *GameClock {
+	return &s.BoardMain.Time
+}
+
+// Tick to redraw the board when the shown seconds change
+func (s *BasicSudoku) Tick() {
+	if played, _ := s.Time.Seconds(); played != s.shownTime {
 		s.Changed = true
-		s.TimeLeft -= sec
-		// if went over 0 - no time left
-		if s.TimeLeft <= 0 {
-			s.TimeLeft = 0
-		}
 	}
 }
-func (s *TwoDoku) TimePass(sec int) {
-	s.BoardMain.TimePass(sec)
+func (s *TwoDoku) Tick() {
+	s.BoardMain.Tick()
 }
 
 // TimeEnd to return whether the timer has ended
 func (s *BasicSudoku) TimeEnd() bool {
-	if s.TimeLeft == 0 {
-		return true
-	}
-	return false
+	return s.Time.Expired()
 }
 func (s *TwoDoku) TimeEnd() bool {
 	return s.BoardMain.TimeEnd()
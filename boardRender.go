@@ -0,0 +1,451 @@
+package main
+
+import (
+	"fmt"
+	"github.com/fatih/color"
+	"io"
+	"strings"
+)
+
+// CellView describes one box of the board for rendering
+type CellView struct {
+	Present   bool      // box is part of the board(TwoDoku has gaps between boards)
+	Value     int       // number in the box, 0 if empty
+	Given     bool      // number was given at the start of the game
+	Correct   bool      // number is the same as in the answers
+	Cursor    bool      // cursor is in the box
+	Highlight bool      // box is highlighted(diagonals of DiagonalSudoku)
+	Conflict  bool      // number is also in a row, column, nonet or diagonal of the box
+	Peer      bool      // box shares a row, column, nonet or diagonal with the cursor
+	Notes     NoteMarks // pencil marks of the box
+}
+
+// BoardView is everything needed to draw the board
+type BoardView struct {
+	Cells     [][]CellView // boxes by row and column
+	NonetSize Vector2      // size of one nonet(width and height)
+	Diagonal  bool         // diagonals must contain all numbers too
+	Cursor    Vector2      // cursor position, -1 -1 if there is no cursor
+	TimeLeft  int          // time left on timer, -1 if there is no timer
+	Elapsed   int          // seconds played
+	Cleanup   bool         // entered numbers are removed from pencil marks of peers
+	CheckMode int          // how mistakes are shown, answers, rules or blind
+	ShowWrong bool         // numbers different from the answers are shown after check
+}
+
+// Renderer draws board views
+type Renderer interface {
+	Render(view BoardView) error
+}
+
+// TerminalRenderer draws board with colors for the terminal
+type TerminalRenderer struct {
+	Out    io.Writer // where to draw
+	Labels int       // labels of rows and columns drawn around the board
+}
+
+// label schemes of rows and columns
+const (
+	LabelsOff     = iota // no labels
+	LabelsLetters        // rows are numbered and columns have letters, boxes are named like G5
+	LabelsNumbers        // rows and columns are numbered, boxes are named like r5c7
+)
+
+// names of label schemes shown to the player
+var labelSchemes = []string{"off", "letters", "numbers"}
+
+// rowLabel returns label of row in scheme
+func rowLabel(row, scheme int) string {
+	if scheme == LabelsNumbers {
+		return fmt.Sprintf("r%d", row+1)
+	}
+	return fmt.Sprint(row + 1)
+}
+
+// colLabel returns label of column in scheme
+func colLabel(col, scheme int) string {
+	if scheme == LabelsNumbers {
+		return fmt.Sprintf("c%d", col+1)
+	}
+	return string(rune('A' + col))
+}
+
+// boxName returns name of the box at pos in scheme, like r5c7 if there are no labels
+func boxName(pos Vector2, scheme int) string {
+	if scheme == LabelsLetters {
+		return colLabel(pos.Ypos, scheme) + rowLabel(pos.Xpos, scheme)
+	}
+	return rowLabel(pos.Xpos, LabelsNumbers) + colLabel(pos.Ypos, LabelsNumbers)
+}
+
+// newBoardView to create view with all boxes present and empty
+func newBoardView(rows, cols int, nonet Vector2) BoardView {
+	view := BoardView{Cells: make([][]CellView, rows), NonetSize: nonet, Cursor: Vector2{-1, -1}}
+	for i := range view.Cells {
+		view.Cells[i] = make([]CellView, cols)
+		for j := range view.Cells[i] {
+			view.Cells[i][j].Present = true
+		}
+	}
+	return view
+}
+
+// fill view boxes from the board starting at offset, diagonals are checked for conflicts and peers if diagonal
+func (s *BasicSudoku) fillView(view *BoardView, offset Vector2, diagonal bool) {
+	// peers of the cursor in this board, boxes of the TwoDoku shared nonet are peers in either board
+	if s.CursorPos.Xpos != -1 {
+		for _, peer := range s.peers(s.CursorPos, diagonal) {
+			view.Cells[peer.Xpos+offset.Xpos][peer.Ypos+offset.Ypos].Peer = true
+		}
+	}
+	for i := 0; i < s.Size; i++ {
+		for j := 0; j < s.Size; j++ {
+			cell := &view.Cells[i+offset.Xpos][j+offset.Ypos]
+			cell.Present = true
+			cell.Value = s.BoardShow[i][j]
+			cell.Given = s.isGiven(Vector2{i, j})
+			cell.Correct = s.BoardShow[i][j] == s.Board[i][j]
+			// boxes of the TwoDoku shared nonet conflict by rules of either board
+			cell.Conflict = cell.Conflict || s.conflicted(Vector2{i, j}, diagonal)
+			cell.Notes = s.notesAt(Vector2{i, j})
+			if s.CursorPos.Xpos == i && s.CursorPos.Ypos == j {
+				cell.Cursor = true
+				view.Cursor = Vector2{i + offset.Xpos, j + offset.Ypos}
+			}
+		}
+	}
+}
+
+// view to create view model of the board, diagonals are checked for conflicts if diagonal
+func (s *BasicSudoku) view(diagonal bool) BoardView {
+	view := newBoardView(s.Size, s.Size, s.NonetSize)
+	s.fillView(&view, Vector2{0, 0}, diagonal)
+	view.Elapsed, view.TimeLeft = s.Time.Seconds()
+	view.Cleanup = s.AutoCleanup
+	view.CheckMode = s.CheckMode
+	view.ShowWrong = s.showWrong
+	return view
+}
+
+// View returns view model of the board
+func (s *BasicSudoku) View() BoardView {
+	return s.view(false)
+}
+func (s *DiagonalSudoku) View() BoardView {
+	view := s.view(true)
+	view.Diagonal = true
+	// highlight both diagonals
+	for i := 0; i < s.Size; i++ {
+		view.Cells[i][i].Highlight = true
+		view.Cells[i][s.Size-i-1].Highlight = true
+	}
+	return view
+}
+func (s *TwoDoku) View() BoardView {
+	// boards overlap in the corner nonet, so they take 15x15 boxes
+	view := newBoardView(15, 15, s.BoardMain.NonetSize)
+	for i := range view.Cells {
+		for j := range view.Cells[i] {
+			view.Cells[i][j].Present = false
+		}
+	}
+	s.BoardAdd.fillView(&view, Vector2{6, 6}, false)
+	// main board is filled last, so its cursor is used in the shared nonet
+	s.BoardMain.fillView(&view, Vector2{0, 0}, false)
+	view.Elapsed, view.TimeLeft = s.BoardMain.Time.Seconds()
+	view.Cleanup = s.BoardMain.AutoCleanup
+	view.CheckMode = s.BoardMain.CheckMode
+	view.ShowWrong = s.BoardMain.showWrong
+	return view
+}
+
+// Print to draw the show board as well as timer and instructions with the renderer
+func (s *BasicSudoku) Print(r Renderer) {
+	if !s.Changed {
+		return
+	}
+	s.Changed = false
+	view := s.View()
+	s.shownTime = view.Elapsed
+	_ = r.Render(view)
+}
+func (s *DiagonalSudoku) Print(r Renderer) {
+	if !s.Changed {
+		return
+	}
+	s.Changed = false
+	view := s.View()
+	s.shownTime = view.Elapsed
+	_ = r.Render(view)
+}
+func (s *TwoDoku) Print(r Renderer) {
+	if !s.BoardMain.Changed && !s.BoardAdd.Changed {
+		return
+	}
+	s.BoardMain.Changed = false
+	s.BoardAdd.Changed = false
+	view := s.View()
+	s.BoardMain.shownTime = view.Elapsed
+	_ = r.Render(view)
+}
+
+// Render to draw manual, board and timer
+func (r *TerminalRenderer) Render(view BoardView) error {
+	var out strings.Builder
+	r.manual(&out, view)
+	r.board(&out, view)
+	// output time left if timer exist, otherwise time played
+	if view.TimeLeft != -1 {
+		_, _ = greenFont.Fprintf(&out, "Time remaining: %02d:%02d", view.TimeLeft/60, view.TimeLeft%60)
+	} else {
+		_, _ = greenFont.Fprintf(&out, "Time played: %02d:%02d", view.Elapsed/60, view.Elapsed%60)
+	}
+	// and the box of the cursor
+	if view.Cursor.Xpos != -1 {
+		_, _ = greenFont.Fprint(&out, "   Box: "+boxName(view.Cursor, r.Labels))
+		if cell := view.Cells[view.Cursor.Xpos][view.Cursor.Ypos]; cell.Value == 0 && !cell.Notes.Empty() {
+			_, _ = blueFont.Fprint(&out, "   Marks: "+marksText(cell.Notes))
+		}
+	}
+	_, _ = fmt.Fprintln(&out)
+	_, err := io.WriteString(r.Out, out.String())
+	return err
+}
+
+// manual to draw common parts of sudoku manual for every board
+func (r *TerminalRenderer) manual(out io.Writer, view BoardView) {
+	cleanup := "off"
+	if view.Cleanup {
+		cleanup = "on"
+	}
+	// full list of keys is shown with ?, so only the switched states are here
+	blueFont.Fprintln(out, "Mistakes: "+checkModes[view.CheckMode]+"(Ctrl+F), cleanup: "+cleanup+"(Ctrl+L), labels: "+labelSchemes[r.Labels]+"(Ctrl+N)")
+	givenFont.Fprint(out, "White")
+	blueFont.Fprint(out, " - given, ")
+	if view.CheckMode != CheckBlind {
+		greenFont.Fprint(out, "Green")
+		blueFont.Fprint(out, " - solved, ")
+	}
+	redFont.Fprint(out, "Red")
+	if view.CheckMode == CheckRules {
+		blueFont.Fprintln(out, " - breaks the rules")
+	} else if view.CheckMode == CheckBlind {
+		blueFont.Fprintln(out, " - incorrect, after Ctrl+W")
+	} else {
+		blueFont.Fprintln(out, " - incorrect")
+	}
+	purpleFont.Fprint(out, "Purple")
+	blueFont.Fprint(out, " - cursor, ")
+	peerFont.Fprint(out, "shaded")
+	blueFont.Fprint(out, " - same row, column or nonet, ")
+	sameFont.Fprint(out, "marked")
+	blueFont.Fprintln(out, " - same number")
+	if view.Diagonal {
+		diagonalFont.Fprint(out, "Yellow")
+		blueFont.Fprintln(out, " - correct diagonal")
+	}
+}
+
+// font to draw the box with, mistakes are shown as chosen by check mode of the view
+func (r *TerminalRenderer) cellFont(cell CellView, view BoardView) *color.Color {
+	incorrect := cell.Value != 0 && !cell.Correct
+	if view.CheckMode == CheckRules && cell.Conflict { // element breaking the rules
+		return redFont
+	} else if (view.CheckMode == CheckAnswers || view.ShowWrong) && incorrect { // incorrect element
+		return redFont
+	} else if cell.Cursor { // element where cursor is located
+		return purpleFont
+	} else if cell.Given { // element given at the start
+		return givenFont
+	} else if cell.Highlight && cell.Value != 0 { // diagonal element
+		return diagonalFont
+	} else if cell.Value != 0 && view.CheckMode != CheckBlind { // correct element
+		return greenFont
+	}
+	return nil
+}
+
+// marksText to write pencil marks in one short line, corner marks follow center marks after a slash
+func marksText(marks NoteMarks) string {
+	symbols := func(mask int) string {
+		var text []byte
+		for _, val := range marked(mask) {
+			text = append(text, cellSymbol(val))
+		}
+		return string(text)
+	}
+	text := symbols(marks.Center)
+	if marks.Corner != 0 {
+		text += " / " + symbols(marks.Corner)
+	}
+	return text
+}
+
+// shade to draw text with background of peers of the cursor and of numbers same as focus
+func shade(text string, cell CellView, focus int) string {
+	if focus != 0 && cell.Value == focus && !cell.Cursor {
+		return sameFont.Sprint(text)
+	} else if cell.Peer {
+		return peerFont.Sprint(text)
+	}
+	return text
+}
+
+// cell to draw the box followed by a space, focus is the number under the cursor
+func (r *TerminalRenderer) cell(out io.Writer, view BoardView, cell CellView, focus int) {
+	font := r.cellFont(cell, view)
+	// handle numbers over 9
+	text := fmt.Sprint(cell.Value)
+	if cell.Value > 9 {
+		// transform to letter format
+		text = string(rune('A' + cell.Value - 10))
+	}
+	if cell.Value == 0 && !cell.Notes.Empty() {
+		// marks are listed under the board for the cursor box, other boxes only show that they have some
+		text = "*"
+		if !cell.Cursor {
+			font = blueFont
+		}
+		// box with a mark of the focused number is highlighted as the number itself
+		if focus != 0 && (cell.Notes.Center|cell.Notes.Corner)&(1<<focus) != 0 {
+			_, _ = fmt.Fprint(out, sameFont.Sprint(font.Sprint(text))+" ")
+			return
+		}
+	}
+	if font != nil {
+		text = font.Sprint(text)
+	}
+	_, _ = fmt.Fprint(out, shade(text, cell, focus)+" ")
+}
+
+// board to draw boxes with nonet borders, absent boxes are left blank
+func (r *TerminalRenderer) board(out io.Writer, view BoardView) {
+	rows := len(view.Cells)
+	if rows == 0 {
+		return
+	}
+	cols := len(view.Cells[0])
+	height, width := view.NonetSize.Xpos, view.NonetSize.Ypos
+	// number under the cursor is highlighted in every box
+	focus := 0
+	if view.Cursor.Xpos != -1 {
+		focus = view.Cells[view.Cursor.Xpos][view.Cursor.Ypos].Value
+	}
+	// box and the space after it
+	cellWidth := 2
+	// rows are labeled on the left, columns above the board
+	margin := ""
+	if r.Labels != LabelsOff {
+		margin = strings.Repeat(" ", len(rowLabel(rows-1, r.Labels))+1)
+		r.colLabels(out, cols, width, cellWidth-1, len(margin))
+	}
+
+	// whether any box of the nonet is present
+	nonetPresent := func(row, col int) bool {
+		if row < 0 || col < 0 || row >= rows/height || col >= cols/width {
+			return false
+		}
+		for i := row * height; i < (row+1)*height; i++ {
+			for j := col * width; j < (col+1)*width; j++ {
+				if view.Cells[i][j].Present {
+					return true
+				}
+			}
+		}
+		return false
+	}
+	// horizontal border above row
+	border := func(row int) {
+		var line strings.Builder
+		above, below := row/height-1, row/height
+		for col := 0; col <= cols/width; col++ {
+			// joint between nonets
+			if nonetPresent(above, col-1) || nonetPresent(above, col) {
+				line.WriteString("|")
+			} else if nonetPresent(below, col-1) || nonetPresent(below, col) {
+				line.WriteString("_")
+			} else {
+				line.WriteString(" ")
+			}
+			if col == cols/width {
+				break
+			}
+			// nonet side
+			if nonetPresent(above, col) || nonetPresent(below, col) {
+				line.WriteString(strings.Repeat("_", width*cellWidth+1))
+			} else {
+				line.WriteString(strings.Repeat(" ", width*cellWidth+1))
+			}
+		}
+		blueFont.Fprintln(out, margin+strings.TrimRight(line.String(), " "))
+	}
+
+	for i, line := range view.Cells {
+		// print horizontal borders for nonets
+		if i%height == 0 {
+			border(i)
+		}
+		if r.Labels != LabelsOff {
+			blueFont.Fprintf(out, "%*s ", len(margin)-1, rowLabel(i, r.Labels))
+		}
+		// spaces are printed only when something follows them
+		spaces := 0
+		for j, cell := range line {
+			// print vertical borders
+			if j%width == 0 {
+				if cell.Present || (j > 0 && line[j-1].Present) {
+					_, _ = fmt.Fprint(out, strings.Repeat(" ", spaces))
+					blueFont.Fprint(out, "|")
+					spaces = 0
+				} else {
+					spaces++
+				}
+				spaces++
+			}
+			if !cell.Present {
+				spaces += cellWidth
+				continue
+			}
+			_, _ = fmt.Fprint(out, strings.Repeat(" ", spaces))
+			spaces = 0
+			r.cell(out, view, cell, focus)
+		}
+		if line[cols-1].Present {
+			blueFont.Fprint(out, "|")
+		}
+		_, _ = fmt.Fprintln(out)
+	}
+	// print last horizontal border
+	border(rows)
+}
+
+// colLabels to draw labels above cols columns in slots of width, labels too wide for the slot are written downwards
+func (r *TerminalRenderer) colLabels(out io.Writer, cols, nonetWidth, slot, margin int) {
+	labels := make([]string, cols)
+	height := 1
+	for j := range labels {
+		labels[j] = colLabel(j, r.Labels)
+		if len(labels[j]) > slot && len(labels[j]) > height {
+			height = len(labels[j])
+		}
+	}
+	for h := 0; h < height; h++ {
+		line := strings.Repeat(" ", margin)
+		for j, label := range labels {
+			// space of the nonet border
+			if j%nonetWidth == 0 {
+				line += "  "
+			}
+			text := label
+			if height > 1 {
+				// labels end on the last line
+				text = " "
+				if at := h - (height - len(label)); at >= 0 {
+					text = label[at : at+1]
+				}
+			}
+			line += fmt.Sprintf("%*s", (slot+len(text))/2, text) + strings.Repeat(" ", slot-(slot+len(text))/2) + " "
+		}
+		blueFont.Fprintln(out, strings.TrimRight(line, " "))
+	}
+}
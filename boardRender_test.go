@@ -0,0 +1,176 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/fatih/color"
+)
+
+// frames of renderFixture drawn with every label scheme
+var goldenFrames = []struct {
+	labels int
+	frame  string
+}{
+	{LabelsOff, `
+Mistakes: answers(Ctrl+F), cleanup: off(Ctrl+L), labels: off(Ctrl+N)
+White - given, Green - solved, Red - incorrect
+Purple - cursor, shaded - same row, column or nonet, marked - same number
+_____________
+| 4 1 | 2 0 |
+| 2 * | 1 0 |
+|_____|_____|
+| 0 0 | 2 0 |
+| 1 0 | 0 3 |
+|_____|_____|
+Time played: 00:00   Box: r3c1
+`},
+	{LabelsLetters, `
+Mistakes: answers(Ctrl+F), cleanup: off(Ctrl+L), labels: letters(Ctrl+N)
+White - given, Green - solved, Red - incorrect
+Purple - cursor, shaded - same row, column or nonet, marked - same number
+    A B   C D
+  _____________
+1 | 4 1 | 2 0 |
+2 | 2 * | 1 0 |
+  |_____|_____|
+3 | 0 0 | 2 0 |
+4 | 1 0 | 0 3 |
+  |_____|_____|
+Time played: 00:00   Box: A3
+`},
+	{LabelsNumbers, `
+Mistakes: answers(Ctrl+F), cleanup: off(Ctrl+L), labels: numbers(Ctrl+N)
+White - given, Green - solved, Red - incorrect
+Purple - cursor, shaded - same row, column or nonet, marked - same number
+     c c   c c
+     1 2   3 4
+   _____________
+r1 | 4 1 | 2 0 |
+r2 | 2 * | 1 0 |
+   |_____|_____|
+r3 | 0 0 | 2 0 |
+r4 | 1 0 | 0 3 |
+   |_____|_____|
+Time played: 00:00   Box: r3c1
+`},
+}
+
+// renderFixture returns 4x4 board with correct and wrong entries and pencil marks
+func renderFixture(t *testing.T) SudokuBoard {
+	t.Helper()
+	board, err := ImportBoard(parseLine(t, "4...2.1...2.1..3"), "square", -1)
+	if err != nil {
+		t.Fatalf("ImportBoard returned %v", err)
+	}
+	board.Place(Vector2{0, 1})
+	board.Enter(1)
+	board.Place(Vector2{0, 2})
+	board.Enter(2)
+	board.Place(Vector2{1, 1})
+	board.Note(3, false)
+	board.Note(4, true)
+	board.Place(Vector2{2, 0})
+	return board
+}
+
+func TestTerminalRendererGolden(t *testing.T) {
+	noColor := color.NoColor
+	color.NoColor = true
+	defer func() { color.NoColor = noColor }()
+
+	board := renderFixture(t)
+	for _, golden := range goldenFrames {
+		t.Run(labelSchemes[golden.labels], func(t *testing.T) {
+			var out strings.Builder
+			if err := (&TerminalRenderer{&out, golden.labels}).Render(board.View()); err != nil {
+				t.Fatalf("Render returned %v", err)
+			}
+			if want := strings.TrimPrefix(golden.frame, "\n"); out.String() != want {
+				t.Errorf("Render drew\n%s\nwant\n%s", out.String(), want)
+			}
+		})
+	}
+}
+
+// renderSize returns width and height of the frame drawn for the board
+func renderSize(t *testing.T, board SudokuBoard) (int, int, string) {
+	t.Helper()
+	var out strings.Builder
+	if err := (&TerminalRenderer{&out, LabelsNumbers}).Render(board.View()); err != nil {
+		t.Fatalf("Render returned %v", err)
+	}
+	width, height := frameSize(parseFrame(out.String()))
+	return width, height, out.String()
+}
+
+func TestRenderNotesKeepFrameSize(t *testing.T) {
+	noColor := color.NoColor
+	color.NoColor = true
+	defer func() { color.NoColor = noColor }()
+
+	tests := []struct {
+		variant string
+		size    int
+		marks   string // marks of the cursor box on the status line
+	}{
+		{"square", 9, "123456789 / 123456789"},
+		{"square", 12, "123456789ABC / 123456789ABC"},
+		{"diagonal", 9, "123456789 / 123456789"},
+		{"twodoku", 9, "123456789 / 123456789"},
+	}
+	for _, test := range tests {
+		t.Run(fmt.Sprintf("%s %d", test.variant, test.size), func(t *testing.T) {
+			board := newBoard(test.variant, test.size, 1, -1, 3)
+			_, boards := shareBoards(board)
+			board.Place(firstEmpty(boards[0]))
+			width, height, _ := renderSize(t, board)
+
+			// every box gets marks and the cursor box every number in the center and the corners
+			board.AutoNotes()
+			for val := 1; val <= test.size; val++ {
+				if board.View().Cells[board.Cursor().Xpos][board.Cursor().Ypos].Notes.Center&(1<<val) == 0 {
+					board.Note(val, false)
+				}
+				board.Note(val, true)
+			}
+			gotWidth, gotHeight, frame := renderSize(t, board)
+			if gotWidth != width || gotHeight != height {
+				t.Errorf("frame with marks is %dx%d, want %dx%d as without marks", gotWidth, gotHeight, width, height)
+			}
+			if want := "Marks: " + test.marks; !strings.Contains(frame, want) {
+				t.Errorf("frame does not list marks of the cursor box as %q:\n%s", want, frame)
+			}
+		})
+	}
+}
+
+func TestCellFont(t *testing.T) {
+	tests := []struct {
+		name string
+		cell CellView
+		mode int
+		want *color.Color
+	}{
+		{"given", CellView{Value: 4, Given: true, Correct: true}, CheckAnswers, givenFont},
+		{"given on diagonal", CellView{Value: 4, Given: true, Correct: true, Highlight: true}, CheckAnswers, givenFont},
+		{"entry on diagonal", CellView{Value: 4, Correct: true, Highlight: true}, CheckAnswers, diagonalFont},
+		{"correct entry", CellView{Value: 4, Correct: true}, CheckAnswers, greenFont},
+		{"wrong entry", CellView{Value: 4}, CheckAnswers, redFont},
+		{"wrong entry under cursor", CellView{Value: 4, Cursor: true}, CheckAnswers, redFont},
+		{"empty cursor", CellView{Cursor: true}, CheckAnswers, purpleFont},
+		{"conflict by rules", CellView{Value: 4, Correct: true, Conflict: true}, CheckRules, redFont},
+		{"wrong entry by rules", CellView{Value: 4}, CheckRules, greenFont},
+		{"wrong entry in blind mode", CellView{Value: 4}, CheckBlind, nil},
+		{"empty box", CellView{}, CheckAnswers, nil},
+	}
+	renderer := &TerminalRenderer{}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := renderer.cellFont(test.cell, BoardView{CheckMode: test.mode}); got != test.want {
+				t.Errorf("cellFont = %v, want %v", got, test.want)
+			}
+		})
+	}
+}
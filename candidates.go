@@ -0,0 +1,131 @@
+package main
+
+// peers returns boxes in the same row, column, nonet and, if diagonal, on the same diagonal as pos
+func (s *BasicSudoku) peers(pos Vector2, diagonal bool) []Vector2 {
+	var peers []Vector2
+	start := Vector2{pos.Xpos - pos.Xpos%s.NonetSize.Xpos, pos.Ypos - pos.Ypos%s.NonetSize.Ypos}
+	for i := 0; i < s.Size; i++ {
+		for j := 0; j < s.Size; j++ {
+			if i == pos.Xpos && j == pos.Ypos {
+				continue
+			}
+			sameNonet := i >= start.Xpos && i < start.Xpos+s.NonetSize.Xpos && j >= start.Ypos && j < start.Ypos+s.NonetSize.Ypos
+			sameDiagonal := diagonal && ((i == j && pos.Xpos == pos.Ypos) || (i+j == s.Size-1 && pos.Xpos+pos.Ypos == s.Size-1))
+			if i == pos.Xpos || j == pos.Ypos || sameNonet || sameDiagonal {
+				peers = append(peers, Vector2{i, j})
+			}
+		}
+	}
+	return peers
+}
+
+// numbersMask returns bit mask with bits of numbers set
+func numbersMask(numbers []int) int {
+	mask := 0
+	for _, val := range numbers {
+		mask |= 1 << val
+	}
+	return mask
+}
+
+// candidates returns mask of numbers that can be entered at pos
+func (s *BasicSudoku) candidates(pos Vector2) int {
+	return numbersMask(s.AvailableNum(pos.Xpos*s.Size+pos.Ypos, s.BoardShow, nil))
+}
+func (s *DiagonalSudoku) candidates(pos Vector2) int {
+	return numbersMask(s.AvailableNum(pos.Xpos*s.Size+pos.Ypos, s.BoardShow, nil))
+}
+
+// fillNotes to write candidates as center marks of every empty box, all marks are undone together
+func (s *BasicSudoku) fillNotes(candidates func(pos Vector2) int) {
+	chained := false
+	for i := 0; i < s.Size; i++ {
+		for j := 0; j < s.Size; j++ {
+			pos := Vector2{i, j}
+			old := s.notesAt(pos)
+			marks := NoteMarks{candidates(pos), old.Corner}
+			if s.BoardShow[i][j] != 0 || marks == old {
+				continue
+			}
+			s.addAction(Change{pos, 0, 0, old, marks, chained})
+			s.setNotes(pos, marks)
+			chained = true
+		}
+	}
+}
+
+// AutoNotes to fill empty boxes with all candidates as center marks
+func (s *BasicSudoku) AutoNotes() {
+	s.fillNotes(s.candidates)
+}
+func (s *DiagonalSudoku) AutoNotes() {
+	s.fillNotes(s.candidates)
+}
+func (s *TwoDoku) AutoNotes() {
+	mainBefore, addBefore := s.BoardMain.CurrentAction, s.BoardAdd.CurrentAction
+	// boxes of the shared nonet follow rules of both boards
+	s.BoardMain.fillNotes(func(pos Vector2) int {
+		mask := s.BoardMain.candidates(pos)
+		if pos.Xpos >= 6 && pos.Ypos >= 6 {
+			mask &= s.BoardAdd.candidates(Vector2{pos.Xpos - 6, pos.Ypos - 6})
+		}
+		return mask
+	})
+	s.BoardAdd.fillNotes(func(pos Vector2) int {
+		mask := s.BoardAdd.candidates(pos)
+		if pos.Xpos <= 2 && pos.Ypos <= 2 {
+			mask &= s.BoardMain.candidates(Vector2{pos.Xpos + 6, pos.Ypos + 6})
+		}
+		return mask
+	})
+	s.record(mainBefore, addBefore)
+}
+
+// cleanNotes to remove val from marks of the peers of pos, following the last move
+func (s *BasicSudoku) cleanNotes(pos Vector2, val int, diagonal bool) {
+	for _, peer := range s.peers(pos, diagonal) {
+		old := s.notesAt(peer)
+		marks := NoteMarks{old.Center &^ (1 << val), old.Corner &^ (1 << val)}
+		if marks == old {
+			continue
+		}
+		current := s.BoardShow[peer.Xpos][peer.Ypos]
+		s.addAction(Change{peer, current, current, old, marks, true})
+		s.setNotes(peer, marks)
+	}
+}
+
+// SetAutoCleanup to choose whether entered numbers are removed from marks of their peers
+func (s *BasicSudoku) SetAutoCleanup(on bool) {
+	s.AutoCleanup = on
+	s.Changed = true
+}
+func (s *TwoDoku) SetAutoCleanup(on bool) {
+	s.BoardMain.SetAutoCleanup(on)
+	s.BoardAdd.SetAutoCleanup(on)
+}
+
+// syncShared to keep only marks present in both boards in the shared nonet, as cleanup happens in one board
+func (s *TwoDoku) syncShared(mainBefore, addBefore int) {
+	for i := 0; i < 3; i++ {
+		for j := 0; j < 3; j++ {
+			posMain, posAdd := Vector2{i + 6, j + 6}, Vector2{i, j}
+			main, add := s.BoardMain.notesAt(posMain), s.BoardAdd.notesAt(posAdd)
+			marks := NoteMarks{main.Center & add.Center, main.Corner & add.Corner}
+			for _, side := range []struct {
+				board  *BasicSudoku
+				pos    Vector2
+				old    NoteMarks
+				before int
+			}{{&s.BoardMain, posMain, main, mainBefore}, {&s.BoardAdd, posAdd, add, addBefore}} {
+				if side.old == marks {
+					continue
+				}
+				current := side.board.BoardShow[side.pos.Xpos][side.pos.Ypos]
+				// chain to the move made in this board, if there was one
+				side.board.addAction(Change{side.pos, current, current, side.old, marks, side.board.CurrentAction != side.before})
+				side.board.setNotes(side.pos, marks)
+			}
+		}
+	}
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+// emptyInRow returns position of the first empty box of the row between columns from and to
+func emptyInRow(t *testing.T, board *BasicSudoku, row, from, to int) Vector2 {
+	t.Helper()
+	for j := from; j <= to; j++ {
+		if board.BoardShow[row][j] == 0 {
+			return Vector2{row, j}
+		}
+	}
+	t.Fatalf("row %d has no empty box between columns %d and %d", row, from, to)
+	return Vector2{-1, -1}
+}
+
+func TestTwoDokuCleanupUndo(t *testing.T) {
+	tests := []struct {
+		name  string
+		entry func(t *testing.T, two *TwoDoku, marked Vector2) Vector2 // position of the entry, marked box is its peer
+	}{
+		{"entry in the shared nonet", func(t *testing.T, two *TwoDoku, marked Vector2) Vector2 {
+			return sharedEmpty(t, two, 2)[1]
+		}},
+		{"entry in the main board", func(t *testing.T, two *TwoDoku, marked Vector2) Vector2 {
+			return emptyInRow(t, &two.BoardMain, marked.Xpos, 0, 5)
+		}},
+		{"entry in the additional board", func(t *testing.T, two *TwoDoku, marked Vector2) Vector2 {
+			pos := emptyInRow(t, &two.BoardAdd, marked.Xpos-6, 3, 8)
+			return Vector2{pos.Xpos + 6, pos.Ypos + 6}
+		}},
+	}
+	const val = 3
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			two := newBoard("twodoku", 9, 1, -1, 5).(*TwoDoku)
+			marked := sharedEmpty(t, two, 1)[0]
+			two.Place(marked)
+			two.Note(val, false)
+			two.Note(val, true)
+			two.Note(val+1, false)
+			entry := test.entry(t, two, marked)
+
+			two.SetAutoCleanup(true)
+			two.Place(entry)
+			if !two.Enter(val) {
+				t.Fatal("Enter in an empty box returned false")
+			}
+			check := func(step string, value int, want NoteMarks) {
+				t.Helper()
+				two.Place(entry)
+				if got := two.View().Cells[entry.Xpos][entry.Ypos].Value; got != value {
+					t.Errorf("%s: value = %d, want %d", step, got, value)
+				}
+				// marks of the shared box stay the same in both boards
+				if main, add := sharedNotes(two, marked); main != want || add != want {
+					t.Errorf("%s: marks = %v in main and %v in additional board, want %v", step, main, add, want)
+				}
+			}
+			cleaned := NoteMarks{1 << (val + 1), 0}
+			check("after entry", val, cleaned)
+			// entry and cleanup of both boards are one move
+			two.Undo()
+			check("undo", 0, NoteMarks{1<<val | 1<<(val+1), 1 << val})
+			two.Redo()
+			check("redo", val, cleaned)
+		})
+	}
+}
+
+func TestTwoDokuAutoNotesUndo(t *testing.T) {
+	two := newBoard("twodoku", 9, 1, -1, 5).(*TwoDoku)
+	empty := notesOf(two)
+	two.AutoNotes()
+	filled := notesOf(two)
+	if reflect.DeepEqual(filled, empty) {
+		t.Fatal("AutoNotes wrote no marks")
+	}
+	for _, pos := range sharedEmpty(t, two, 1) {
+		if main, add := sharedNotes(two, pos); main != add || main.Center == 0 {
+			t.Errorf("marks of shared box %v = %v in main and %v in additional board, want the same candidates", pos, main, add)
+		}
+	}
+	// marks of both boards are one move
+	two.Undo()
+	if got := notesOf(two); !reflect.DeepEqual(got, empty) {
+		t.Errorf("marks after undo = %v, want none", got)
+	}
+	two.Redo()
+	if got := notesOf(two); !reflect.DeepEqual(got, filled) {
+		t.Errorf("marks after redo = %v, want %v", got, filled)
+	}
+}
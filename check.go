@@ -0,0 +1,97 @@
+package main
+
+import "time"
+
+// check modes, how mistakes are shown to the player
+const (
+	CheckAnswers = iota // numbers different from the answers are shown
+	CheckRules          // numbers breaking the rules are shown
+	CheckBlind          // nothing is shown until the board is checked
+)
+
+// names of check modes shown to the player
+var checkModes = []string{"answers", "rules", "blind"}
+
+// time added to play time for checking the board, showing wrong numbers costs twice as much
+const checkPenalty = 30 * time.Second
+
+// SetCheckMode to choose how mistakes are shown
+func (s *BasicSudoku) SetCheckMode(mode int) {
+	s.CheckMode = mode
+	s.Changed = true
+}
+func (s *TwoDoku) SetCheckMode(mode int) {
+	s.BoardMain.SetCheckMode(mode)
+	s.BoardAdd.SetCheckMode(mode)
+}
+
+// conflicted returns whether number at pos is also in one of its peers
+func (s *BasicSudoku) conflicted(pos Vector2, diagonal bool) bool {
+	val := s.BoardShow[pos.Xpos][pos.Ypos]
+	if val == 0 {
+		return false
+	}
+	for _, peer := range s.peers(pos, diagonal) {
+		if s.BoardShow[peer.Xpos][peer.Ypos] == val {
+			return true
+		}
+	}
+	return false
+}
+
+// IsFilled returns whether every box has a number
+func (s *BasicSudoku) IsFilled() bool {
+	for _, line := range s.BoardShow {
+		for _, element := range line {
+			if element == 0 {
+				return false
+			}
+		}
+	}
+	return true
+}
+func (s *TwoDoku) IsFilled() bool {
+	return s.BoardMain.IsFilled() && s.BoardAdd.IsFilled()
+}
+
+// wrong returns positions of numbers different from the answers, moved by offset
+func (s *BasicSudoku) wrong(offset Vector2) []Vector2 {
+	var wrong []Vector2
+	for i, line := range s.BoardShow {
+		for j, element := range line {
+			if element != 0 && element != s.Board[i][j] {
+				wrong = append(wrong, Vector2{i + offset.Xpos, j + offset.Ypos})
+			}
+		}
+	}
+	return wrong
+}
+
+// checkCost returns time penalty of the check, show if wrong numbers are shown
+func checkCost(show bool) time.Duration {
+	if show {
+		return 2 * checkPenalty
+	}
+	return checkPenalty
+}
+
+// Check to return positions of wrong numbers with time penalty, they are shown until the next move if show
+func (s *BasicSudoku) Check(show bool) []Vector2 {
+	s.Time.Penalize(checkCost(show))
+	s.showWrong = show
+	s.Changed = true
+	return s.wrong(Vector2{0, 0})
+}
+func (s *TwoDoku) Check(show bool) []Vector2 {
+	// time is counted by the main board
+	wrong := s.BoardMain.Check(show)
+	s.BoardAdd.showWrong = show
+	s.BoardAdd.Changed = true
+	// shared nonet is already checked in the main board
+	for _, pos := range s.BoardAdd.wrong(Vector2{6, 6}) {
+		if pos.Xpos > 8 || pos.Ypos > 8 {
+			wrong = append(wrong, pos)
+		}
+	}
+	return wrong
+}
@@ -0,0 +1,92 @@
+package main
+
+import "time"
+
+// GameClock measures play time with the monotonic clock, paused intervals are not counted
+type GameClock struct {
+	Limit   time.Duration // time for the game, 0 if there is no timer
+	Played  time.Duration // time played before the clock was last started
+	started time.Time     // when the clock was started, zero if it is stopped
+}
+
+// newClock to create stopped clock with limit in seconds, -1 for no timer
+func newClock(limit int) GameClock {
+	if limit < 0 {
+		return GameClock{}
+	}
+	return GameClock{Limit: time.Duration(limit) * time.Second}
+}
+
+// clockFromSeconds to create stopped clock from whole seconds left(-1 for no timer) and played
+func clockFromSeconds(left, played int) GameClock {
+	clock := GameClock{Played: time.Duration(played) * time.Second}
+	if left >= 0 {
+		clock.Limit = clock.Played + time.Duration(left)*time.Second
+	}
+	return clock
+}
+
+// Start the clock if it is stopped
+func (c *GameClock) Start() {
+	if c.started.IsZero() {
+		c.started = time.Now()
+	}
+}
+
+// Stop the clock and count time since it was started
+func (c *GameClock) Stop() {
+	if !c.started.IsZero() {
+		c.Played += time.Since(c.started)
+		c.started = time.Time{}
+	}
+}
+
+// Reset to count play time from zero, running clock keeps running
+func (c *GameClock) Reset() {
+	c.Played = 0
+	if !c.started.IsZero() {
+		c.started = time.Now()
+	}
+}
+
+// Penalize to add penalty to play time
+func (c *GameClock) Penalize(penalty time.Duration) {
+	c.Played += penalty
+}
+
+// Elapsed returns play time
+func (c *GameClock) Elapsed() time.Duration {
+	if c.started.IsZero() {
+		return c.Played
+	}
+	return c.Played + time.Since(c.started)
+}
+
+// Timed returns whether the game has time limit
+func (c *GameClock) Timed() bool {
+	return c.Limit > 0
+}
+
+// Remaining returns time left on the timer, 0 if there is no timer
+func (c *GameClock) Remaining() time.Duration {
+	if !c.Timed() || c.Elapsed() >= c.Limit {
+		return 0
+	}
+	return c.Limit - c.Elapsed()
+}
+
+// Expired returns whether the time limit has passed
+func (c *GameClock) Expired() bool {
+	return c.Timed() && c.Elapsed() >= c.Limit
+}
+
+// Seconds returns whole seconds played and left, left is -1 if there is no timer
+func (c *GameClock) Seconds() (played, left int) {
+	played = int(c.Elapsed() / time.Second)
+	left = -1
+	if c.Timed() {
+		// round up, so 00:00 is shown only when time is over
+		left = int((c.Remaining() + time.Second - 1) / time.Second)
+	}
+	return played, left
+}
@@ -3,43 +3,37 @@ package main
 import (
 	"fmt"
 	"github.com/inancgumus/screen"
-	"golang.org/x/sys/windows"
-	"os"
-	"runtime"
-	"strings"
 )
 
-func init() {
+// InitConsole to prepare console for interactive game
+func (t *Terminal) InitConsole() {
 	screen.Clear()
 	screen.MoveTopLeft()
+	watchResize(t.resized)
 }
 
-// ClearConsole clear console
-func ClearConsole() {
-	// move to top left corner of console
-	screen.MoveTopLeft()
-	windth, height := screen.Size()
-	// override all text with spaces
-	fmt.Print(strings.Repeat(strings.Repeat(" ", windth)+"\n", height))
+// ClearConsole clear console before text is printed past the screen buffer, like when the game is left
+func (t *Terminal) ClearConsole() {
+	// screen buffer has to repaint after the console is cleared
+	t.console.Invalidate()
+	t.frame = ""
+	screen.Clear()
 	screen.MoveTopLeft()
 }
 
-// DisableCursor make cursor invisible (supports linux and windows)
+// DisableCursor make cursor invisible (supports linux, macOS and windows)
 func DisableCursor() {
-	if runtime.GOOS == "windows" {
-		stdout := windows.Handle(os.Stdout.Fd())
-		var originalMode uint32
-		// enable ANSI escape codes
-		windows.GetConsoleMode(stdout, &originalMode)
-		windows.SetConsoleMode(stdout, originalMode|windows.ENABLE_VIRTUAL_TERMINAL_PROCESSING)
-	}
+	// make sure console understands ANSI escape codes
+	enableANSI()
 	// ANSI escape code to disable cursor
 	fmt.Print("\033[?25l")
 }
 
-// EnableCursor make terminal cursor visible (supports linux and windows)
-func EnableCursor() {
+// EnableCursor make terminal cursor visible (supports linux, macOS and windows)
+func (t *Terminal) EnableCursor() {
 	// ANSI escape code to enable cursor
 	fmt.Print("\033[?25h")
-	ClearConsole()
+	t.ClearConsole()
+	// give console back in its original mode
+	restoreANSI()
 }
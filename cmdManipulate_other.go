@@ -0,0 +1,30 @@
+//go:build !windows
+
+package main
+
+import (
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+// enableANSI does nothing, as linux and macOS terminals support ANSI escape codes by default
+func enableANSI() {}
+
+// restoreANSI does nothing, as enableANSI did not change the terminal
+func restoreANSI() {}
+
+// watchResize to notify ch every time terminal window is resized
+func watchResize(ch chan<- struct{}) {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGWINCH)
+	go func() {
+		for range signals {
+			// one pending notification is enough
+			select {
+			case ch <- struct{}{}:
+			default:
+			}
+		}
+	}()
+}
@@ -0,0 +1,55 @@
+//go:build windows
+
+package main
+
+import (
+	"github.com/inancgumus/screen"
+	"golang.org/x/sys/windows"
+	"os"
+	"time"
+)
+
+// console mode before ANSI escape codes were enabled
+var originalMode uint32
+
+// whether originalMode has to be restored on exit
+var modeChanged = false
+
+// enableANSI to turn on ANSI escape codes processing in windows console
+func enableANSI() {
+	stdout := windows.Handle(os.Stdout.Fd())
+	// output is not a console(redirected to file or pipe)
+	if windows.GetConsoleMode(stdout, &originalMode) != nil {
+		return
+	}
+	modeChanged = windows.SetConsoleMode(stdout, originalMode|windows.ENABLE_VIRTUAL_TERMINAL_PROCESSING) == nil
+}
+
+// restoreANSI to return console to the mode it had before enableANSI
+func restoreANSI() {
+	if modeChanged {
+		_ = windows.SetConsoleMode(windows.Handle(os.Stdout.Fd()), originalMode)
+		modeChanged = false
+	}
+}
+
+// watchResize to notify ch every time console window is resized,
+// windows console has no resize signal, so its size is checked regularly
+func watchResize(ch chan<- struct{}) {
+	go func() {
+		width, height := screen.Size()
+		for {
+			time.Sleep(time.Second / 4)
+			newWidth, newHeight := screen.Size()
+			if newWidth == width && newHeight == height {
+				continue
+			}
+			width, height = newWidth, newHeight
+			// one pending notification is enough
+			select {
+			case ch <- struct{}{}:
+			default:
+			}
+		}
+	}()
+}
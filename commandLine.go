@@ -0,0 +1,338 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"math/rand"
+	"os"
+	"strings"
+)
+
+// exit codes of command line commands
+const (
+	exitOk      = 0 // command succeeded
+	exitFailure = 1 // some puzzles are invalid or could not be solved
+	exitUsage   = 2 // wrong arguments
+)
+
+// usage of the command line
+const cliUsage = `Usage:
+  sudoku                                  start interactive game
+  sudoku generate [flags]                 generate new puzzles
+  sudoku solve [flags] <file>             solve puzzles from file ("-" for stdin)
+  sudoku validate [flags] <file>          check that puzzles have exactly one solution
+  sudoku grade [flags] <file>             estimate difficulty of puzzles
+  sudoku play [--load <save> | --code <code> | --variant <type> [--seed <n>]] [--headless]
+                                          start game from save, share code or new board,
+                                          --headless reads moves from stdin
+
+Run "sudoku <command> -h" to see command flags.
+`
+
+// puzzle result printed with --json
+type puzzleResult struct {
+	Puzzle     int      `json:"puzzle"`               // index of the puzzle, starting with 1
+	Variant    string   `json:"variant"`              // board type
+	Size       int      `json:"size"`                 // board size
+	Status     string   `json:"status"`               // solved, invalid, unsolvable or multiple
+	Givens     []string `json:"givens,omitempty"`     // puzzle in one line format
+	Solution   []string `json:"solution,omitempty"`   // solution in one line format
+	Difficulty string   `json:"difficulty,omitempty"` // easy, medium or hard
+	Seed       *int64   `json:"seed,omitempty"`       // seed the puzzle was generated from, play --seed gives it again
+}
+
+// runCommand to execute command line arguments, returns exit code
+func runCommand(args []string, stdout, stderr io.Writer) int {
+	switch args[0] {
+	case "generate":
+		return cmdGenerate(args[1:], stdout, stderr)
+	case "solve", "validate", "grade":
+		return cmdPuzzles(args[0], args[1:], stdout, stderr)
+	case "play":
+		return cmdPlay(args[1:], stderr)
+	case "help", "-h", "--help":
+		_, _ = fmt.Fprint(stdout, cliUsage)
+		return exitOk
+	}
+	_, _ = fmt.Fprintf(stderr, "unknown command %q\n%s", args[0], cliUsage)
+	return exitUsage
+}
+
+// parseArgs to parse flags placed before and after positional arguments
+func parseArgs(fs *flag.FlagSet, args []string) ([]string, error) {
+	var positional []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			return nil, err
+		}
+		args = fs.Args()
+		if len(args) == 0 {
+			return positional, nil
+		}
+		positional = append(positional, args[0])
+		args = args[1:]
+	}
+}
+
+// parse variant name to board type used by menus
+func parseVariant(variant string) (string, error) {
+	switch variant {
+	case "square", "basic", "classic":
+		return "square", nil
+	case "diagonal", "twodoku":
+		return variant, nil
+	}
+	return "", fmt.Errorf("unknown variant %q (square, diagonal or twodoku)", variant)
+}
+
+// parse difficulty name to its index
+func parseDifficulty(difficulty string) (int, error) {
+	for index, name := range gameOptions[2] {
+		if name == difficulty {
+			return index, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown difficulty %q (easy, medium or hard)", difficulty)
+}
+
+// parse format name to its index
+func parseFormat(format string) (int, error) {
+	switch format {
+	case "line":
+		return FormatLine, nil
+	case "grid":
+		return FormatGrid, nil
+	case "sdk":
+		return FormatSdk, nil
+	}
+	return 0, fmt.Errorf("unknown format %q (line, grid or sdk)", format)
+}
+
+// generate new puzzles
+func cmdGenerate(args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("generate", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	variant := fs.String("variant", "square", "board type: square, diagonal or twodoku")
+	size := fs.Int("size", 9, "board size: 4, 6, 9 or 12 (diagonal and twodoku are 9 only)")
+	difficulty := fs.String("difficulty", "easy", "difficulty: easy, medium or hard")
+	count := fs.Int("count", 1, "number of puzzles")
+	seed := fs.Int64("seed", 0, "random seed, 0 for a random one")
+	format := fs.String("format", "line", "output format: line, grid or sdk")
+	solution := fs.Bool("solution", false, "print solution after every puzzle")
+	asJSON := fs.Bool("json", false, "print one JSON object per puzzle")
+	if positional, err := parseArgs(fs, args); err != nil || len(positional) != 0 {
+		if err == nil {
+			_, _ = fmt.Fprintln(stderr, "generate does not take arguments")
+		}
+		return exitUsage
+	}
+
+	// validate flags
+	boardType, err := parseVariant(*variant)
+	if err == nil && !containsInt(importSizes, *size) {
+		err = fmt.Errorf("unsupported size %d", *size)
+	}
+	if err == nil && boardType != "square" && *size != 9 {
+		err = fmt.Errorf("%s boards are 9x9 only", boardType)
+	}
+	level := 0
+	if err == nil {
+		level, err = parseDifficulty(*difficulty)
+	}
+	formatIndex := 0
+	if err == nil {
+		formatIndex, err = parseFormat(*format)
+	}
+	if err == nil && *count < 1 {
+		err = errors.New("count must be positive")
+	}
+	if err != nil {
+		_, _ = fmt.Fprintln(stderr, err)
+		return exitUsage
+	}
+
+	// same seed gives the same puzzles unless generation runs out of time
+	if *seed == 0 {
+		*seed = rand.Int63()
+	}
+	// every puzzle gets its own seed drawn from the seed of the run
+	seeds := rand.New(rand.NewSource(*seed))
+	// text output has no room for seeds, so the seed of the run goes to stderr
+	if !*asJSON {
+		_, _ = fmt.Fprintf(stderr, "seed: %d (--seed %d generates the same puzzles)\n", *seed, *seed)
+	}
+
+	encoder := json.NewEncoder(stdout)
+	for i := 0; i < *count; i++ {
+		puzzleSeed := seeds.Int63()
+		generated := newBoard(boardType, *size, level, -1, puzzleSeed)
+		if *asJSON {
+			_ = encoder.Encode(puzzleResult{
+				Puzzle:     i + 1,
+				Variant:    boardType,
+				Size:       *size,
+				Status:     "generated",
+				Givens:     strings.Fields(generated.Export(ExportGivens, FormatLine)),
+				Solution:   strings.Fields(generated.Export(ExportSolution, FormatLine)),
+				Difficulty: *difficulty,
+				Seed:       &puzzleSeed,
+			})
+			continue
+		}
+		_, _ = fmt.Fprint(stdout, generated.Export(ExportGivens, formatIndex))
+		if *solution {
+			_, _ = fmt.Fprint(stdout, generated.Export(ExportSolution, formatIndex))
+		}
+	}
+	return exitOk
+}
+
+// solve, validate or grade puzzles from file
+func cmdPuzzles(command string, args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet(command, flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	variant := fs.String("variant", "square", "board type: square or diagonal")
+	asJSON := fs.Bool("json", false, "print one JSON object per puzzle")
+	positional, err := parseArgs(fs, args)
+	if err != nil {
+		return exitUsage
+	}
+	if len(positional) != 1 {
+		_, _ = fmt.Fprintf(stderr, "%s takes exactly one file\n", command)
+		return exitUsage
+	}
+	boardType, err := parseVariant(*variant)
+	if err == nil && boardType == "twodoku" {
+		err = errors.New("twodoku puzzles can not be read from files")
+	}
+	if err != nil {
+		_, _ = fmt.Fprintln(stderr, err)
+		return exitUsage
+	}
+
+	// read puzzles from file or stdin
+	var puzzles [][][]int
+	if positional[0] == "-" {
+		puzzles, err = ParsePuzzles(os.Stdin)
+	} else {
+		puzzles, err = ParsePuzzleFile(positional[0])
+	}
+	if err != nil {
+		_, _ = fmt.Fprintln(stderr, err)
+		return exitFailure
+	}
+
+	code := exitOk
+	encoder := json.NewEncoder(stdout)
+	for index, grid := range puzzles {
+		result := puzzleResult{Puzzle: index + 1, Variant: boardType, Size: len(grid), Givens: []string{strings.TrimSpace(formatLine(grid))}}
+		count, solution := solveGrid(grid, nonetSize(len(grid)), boardType == "diagonal", 2)
+		switch count {
+		case -1:
+			result.Status = "invalid"
+		case 0:
+			result.Status = "unsolvable"
+		case 1:
+			result.Status = "solved"
+		default:
+			result.Status = "multiple"
+		}
+		if command == "solve" && count == 1 {
+			result.Solution = []string{strings.TrimSpace(formatLine(solution))}
+		}
+		if command == "grade" && count == 1 {
+			result.Difficulty = gameOptions[2][gradeGrid(grid, nonetSize(len(grid)), boardType == "diagonal")]
+		}
+		if count != 1 {
+			code = exitFailure
+		}
+
+		if *asJSON {
+			_ = encoder.Encode(result)
+			continue
+		}
+		// one line per puzzle: solution, status or difficulty
+		switch {
+		case result.Solution != nil:
+			_, _ = fmt.Fprintln(stdout, result.Solution[0])
+		case result.Difficulty != "":
+			_, _ = fmt.Fprintln(stdout, result.Difficulty)
+		case command == "validate" && count == 1:
+			_, _ = fmt.Fprintln(stdout, "valid")
+		default:
+			_, _ = fmt.Fprintln(stdout, result.Status)
+		}
+	}
+	return code
+}
+
+// start game from save, share code or new board
+func cmdPlay(args []string, stderr io.Writer) int {
+	fs := flag.NewFlagSet("play", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	load := fs.String("load", "", "save file to continue")
+	code := fs.String("code", "", "share code to start from")
+	variant := fs.String("variant", "", "start new board: square, diagonal or twodoku")
+	size := fs.Int("size", 9, "size of the new board")
+	difficulty := fs.String("difficulty", "easy", "difficulty of the new board")
+	seed := fs.Int64("seed", 0, "random seed of the new board, 0 for a random one")
+	headless := fs.Bool("headless", false, "read moves from stdin instead of the keyboard")
+	if positional, err := parseArgs(fs, args); err != nil || len(positional) != 0 {
+		return exitUsage
+	}
+	sources := 0
+	for _, source := range []string{*load, *code, *variant} {
+		if source != "" {
+			sources++
+		}
+	}
+	if sources > 1 {
+		_, _ = fmt.Fprintln(stderr, "use only one of --load, --code or --variant")
+		return exitUsage
+	}
+	// headless game always needs a board
+	if *headless && sources == 0 {
+		*variant = "square"
+	}
+
+	// prepare the board before the terminal is taken over
+	var board SudokuBoard
+	switch {
+	case *load != "":
+		loaded, err := loadGameFile(*load)
+		if err != nil {
+			_, _ = fmt.Fprintln(stderr, err)
+			return exitFailure
+		}
+		board = loaded
+	case *code != "":
+		shared, err := FromShareCode(*code)
+		if err != nil {
+			_, _ = fmt.Fprintln(stderr, err)
+			return exitFailure
+		}
+		board = shared
+	case *variant != "":
+		boardType, err := parseVariant(*variant)
+		level := 0
+		if err == nil {
+			level, err = parseDifficulty(*difficulty)
+		}
+		if err == nil && (!containsInt(importSizes, *size) || (boardType != "square" && *size != 9)) {
+			err = fmt.Errorf("unsupported size %d", *size)
+		}
+		if err != nil {
+			_, _ = fmt.Fprintln(stderr, err)
+			return exitUsage
+		}
+		board = newBoard(boardType, *size, level, -1, *seed)
+	}
+
+	if *headless {
+		return runHeadless(board, os.Stdin, os.Stdout)
+	}
+	return play(board)
+}
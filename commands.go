@@ -0,0 +1,193 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// command typed in headless mode or in the game prompt
+type command struct {
+	name  string                                                      // first word of the command
+	alias string                                                      // other name of the command
+	args  string                                                      // arguments shown in help
+	help  string                                                      // what the command does
+	min   int                                                         // least number of arguments
+	max   int                                                         // most number of arguments, -1 for any
+	run   func(board SudokuBoard, args []string, out io.Writer) error // run the command on the board
+}
+
+// commands of headless mode and the game prompt, set in init as help lists them
+var commands []command
+
+func init() {
+	commands = []command{
+		{"set", "", "<row> <col> <value>", "enter value in the box", 3, 3, func(board SudokuBoard, args []string, out io.Writer) error {
+			if err := placeAt(board, args[0]+" "+args[1]); err != nil {
+				return err
+			}
+			return enterValue(board, args[2])
+		}},
+		{"goto", "", "<row> <col> | r5c7 | G5", "move cursor to the box", 1, 2, func(board SudokuBoard, args []string, out io.Writer) error {
+			return placeAt(board, strings.Join(args, " "))
+		}},
+		{"enter", "", "<value>", "enter value at the cursor", 1, 1, func(board SudokuBoard, args []string, out io.Writer) error {
+			return enterValue(board, args[0])
+		}},
+		{"note", "", "<value>...", "toggle center pencil marks at the cursor", 1, -1, func(board SudokuBoard, args []string, out io.Writer) error {
+			return toggleMarks(board, args, false)
+		}},
+		{"corner", "", "<value>...", "toggle corner pencil marks at the cursor", 1, -1, func(board SudokuBoard, args []string, out io.Writer) error {
+			return toggleMarks(board, args, true)
+		}},
+		{"erase", "", "", "clear value and pencil marks at the cursor", 0, 0, func(board SudokuBoard, args []string, out io.Writer) error {
+			if !board.Erase() {
+				return errors.New("box can not be erased")
+			}
+			return nil
+		}},
+		{"candidates", "", "", "fill empty boxes with all candidates as pencil marks", 0, 0, func(board SudokuBoard, args []string, out io.Writer) error {
+			board.AutoNotes()
+			return nil
+		}},
+		{"cleanup", "", "on|off", "remove entered values from pencil marks of peers", 1, 1, func(board SudokuBoard, args []string, out io.Writer) error {
+			if args[0] != "on" && args[0] != "off" {
+				return fmt.Errorf("cleanup is on or off, not %q", args[0])
+			}
+			board.SetAutoCleanup(args[0] == "on")
+			return nil
+		}},
+		{"check", "", "[show]", "count wrong values, list them with show, costs play time", 0, 1, func(board SudokuBoard, args []string, out io.Writer) error {
+			if len(args) == 1 && args[0] != "show" {
+				return fmt.Errorf("check takes show, not %q", args[0])
+			}
+			wrong := board.Check(len(args) == 1)
+			_, _ = fmt.Fprintln(out, "wrong:", len(wrong))
+			if len(args) == 1 {
+				for _, pos := range wrong {
+					_, _ = fmt.Fprintln(out, pos.Xpos+1, pos.Ypos+1)
+				}
+			}
+			return nil
+		}},
+		{"undo", "", "", "undo last move", 0, 0, func(board SudokuBoard, args []string, out io.Writer) error {
+			board.Undo()
+			return nil
+		}},
+		{"redo", "", "", "redo last undone move", 0, 0, func(board SudokuBoard, args []string, out io.Writer) error {
+			board.Redo()
+			return nil
+		}},
+		{"hint", "", "", "reveal random box", 0, 0, func(board SudokuBoard, args []string, out io.Writer) error {
+			board.RevealRandom()
+			return nil
+		}},
+		{"show", "", "", "print the board", 0, 0, func(board SudokuBoard, args []string, out io.Writer) error {
+			_, _ = fmt.Fprint(out, board.Export(ExportProgress, FormatGrid))
+			return nil
+		}},
+		{"save", "", "[name]", "save the game, to name.sudo if name is given", 0, 1, func(board SudokuBoard, args []string, out io.Writer) error {
+			path, err := board.SaveGame(strings.Join(args, ""))
+			if err == nil {
+				_, _ = fmt.Fprintln(out, "saved to", path)
+			}
+			return err
+		}},
+		{"save-secure", "", "[name]", "save the game without answers", 0, 1, func(board SudokuBoard, args []string, out io.Writer) error {
+			path, err := board.SaveSecure(strings.Join(args, ""))
+			if err == nil {
+				_, _ = fmt.Fprintln(out, "saved to", path)
+			}
+			return err
+		}},
+		{"seed", "", "", "print random seed of the puzzle, play it again with play --seed", 0, 0, func(board SudokuBoard, args []string, out io.Writer) error {
+			_, boards := shareBoards(board)
+			if len(boards) == 0 || boards[0].Seed == 0 {
+				return errors.New("puzzle was not generated from a seed")
+			}
+			_, _ = fmt.Fprintln(out, "seed:", boards[0].Seed)
+			return nil
+		}},
+		{"restart", "", "", "clear everything entered and start the time again", 0, 0, func(board SudokuBoard, args []string, out io.Writer) error {
+			board.Restart()
+			return nil
+		}},
+		{"help", "", "", "print this help", 0, 0, func(board SudokuBoard, args []string, out io.Writer) error {
+			writeHelp(out)
+			return nil
+		}},
+		{"quit", "exit", "", "stop the game", 0, 0, func(board SudokuBoard, args []string, out io.Writer) error {
+			return errQuit
+		}},
+	}
+}
+
+// findCommand returns command called name, nil if there is none
+func findCommand(name string) *command {
+	for i := range commands {
+		if commands[i].name == name || (commands[i].alias != "" && commands[i].alias == name) {
+			return &commands[i]
+		}
+	}
+	return nil
+}
+
+// runBoardCommand to run command in fields on the board, output is written to out
+func runBoardCommand(board SudokuBoard, fields []string, out io.Writer) error {
+	cmd := findCommand(fields[0])
+	if cmd == nil {
+		return fmt.Errorf("unknown command %q, type help to see all commands", fields[0])
+	}
+	args := fields[1:]
+	if len(args) < cmd.min || (cmd.max != -1 && len(args) > cmd.max) {
+		return fmt.Errorf("usage: %s", strings.TrimSpace(cmd.name+" "+cmd.args))
+	}
+	return cmd.run(board, args, out)
+}
+
+// writeHelp to list all commands
+func writeHelp(out io.Writer) {
+	_, _ = fmt.Fprintln(out, "Commands (rows and columns start with 1):")
+	for _, cmd := range commands {
+		_, _ = fmt.Fprintf(out, "  %-26s%s\n", strings.TrimSpace(cmd.name+" "+cmd.args), cmd.help)
+	}
+}
+
+// enterValue to enter value at the cursor and report whether it changed anything
+func enterValue(board SudokuBoard, value string) error {
+	val, err := parseValue(value)
+	if err != nil {
+		return err
+	}
+	if !board.Enter(val) {
+		return fmt.Errorf("value %s can not be entered here", value)
+	}
+	return nil
+}
+
+// placeAt to move cursor to the box named by reference
+func placeAt(board SudokuBoard, reference string) error {
+	pos, err := parseReference(reference)
+	if err != nil {
+		return err
+	}
+	if !board.Place(pos) {
+		return fmt.Errorf("box %s is not on the board", reference)
+	}
+	return nil
+}
+
+// toggleMarks to toggle center or corner pencil marks of values at the cursor
+func toggleMarks(board SudokuBoard, values []string, corner bool) error {
+	for _, value := range values {
+		val, err := parseValue(value)
+		if err != nil {
+			return err
+		}
+		if !board.Note(val, corner) {
+			return fmt.Errorf("mark %s can not be written here", value)
+		}
+	}
+	return nil
+}
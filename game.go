@@ -1,153 +1,282 @@
 package main
 
 import (
+	"fmt"
 	"github.com/eiannone/keyboard"
-	"time"
+	"strings"
 )
 
-// timer thread function
-func timeControl(exit *bool) {
+// wait for the next key press, the shown menu is laid out again when console is resized
+func (t *Terminal) waitKey() (rune, keyboard.Key) {
 	for {
-		// wait 1 second and decrement board play time if game in not on pause
-		time.Sleep(time.Second)
-		if !*exit {
-			board.TimePass(1)
+		select {
+		case event := <-t.keys:
+			return event.Rune, event.Key
+		case <-t.resized:
+			t.console.Invalidate()
+			_ = t.console.Draw(t.frame)
 		}
 	}
 }
 
-// print thread function
-func printSudo(exit *bool) {
-	// maximum fps
-	fps := time.Duration(100)
-	for {
-		// print if game in not on pause and there is info to display
-		time.Sleep(time.Second / fps)
-		if !*exit && board.Display() {
-			ClearConsole()
-			blueFont.Println("Press Esc to exit or pause")
-			board.Print()
-		}
+// show menu frame centered on the console, only changes of the screen are drawn
+func (t *Terminal) show(frame string) {
+	t.frame = frame
+	_ = t.console.Draw(frame)
+}
+
+// draw the board with labels and message under the controls in memory, the session shows only changes of the frame
+func drawBoard(session *Session, noteMode, labels int, message string) {
+	var frame strings.Builder
+	blueFont.Fprintln(&frame, "Esc - pause, ? - all keys, : - command, Space - notes("+noteModes[noteMode]+")")
+	if message != "" {
+		redFont.Fprintln(&frame, message)
 	}
+	session.board.Print(&TerminalRenderer{&frame, labels})
+	session.Draw(frame.String())
 }
 
-// key reader thread function
-func readKeys(r *rune, k *keyboard.Key, b *bool) {
-	for {
-		// wait for rune and key
-		*r, *k, _ = keyboard.GetKey()
-		// bool key found
-		*b = true
+// keys of the game, shown with ? so the manual above the board stays short
+var gameKeys = []string{
+	"Arrows - move, the cursor wraps around the edges",
+	"1-9 and A-C - enter number, Delete, Backspace, 0 or . - erase",
+	"Space - switch between numbers, center and corner pencil marks",
+	"* - box with pencil marks, the cursor box lists them as center / corner marks",
+	"Tab/Shift+Tab(or Ctrl+B) - next/previous empty box, Ctrl+G - go to box",
+	"PgDn/PgUp - next/previous nonet, Home/End - row edges, Ctrl+N - labels",
+	"Ctrl+Z - undo, Ctrl+Y - redo, Ctrl+R - reveal random box",
+	"Ctrl+A - fill pencil marks, Ctrl+L - remove entered numbers from marks",
+	"Ctrl+F - show mistakes by answers, rules or not at all",
+	"Ctrl+K - check board, Ctrl+W - show wrong numbers, both add time",
+	": - type a command, Esc - pause, save or leave the game",
+}
+
+// show every key of the game until any key is pressed
+func (t *Terminal) showKeys() {
+	var frame strings.Builder
+	for _, line := range gameKeys {
+		blueFont.Fprintln(&frame, line)
 	}
+	blueFont.Fprintln(&frame, "Press any key to continue")
+	t.show(frame.String())
+	t.waitKey()
 }
 
-// char, key and whether it was gotten
-var char rune
-var key keyboard.Key
-var keyBool = false
+// isShiftTab reports whether the key is Shift+Tab, keyboard reads its ESC [ Z as Esc with [.
+// The rest of the sequence is dropped, so Alt+[ and other unknown sequences starting
+// with ESC [ (like Shift+arrows) move back too, plain Esc still pauses the game
+func isShiftTab(char rune, key keyboard.Key) bool {
+	return key == keyboard.KeyEsc && char == '['
+}
 
 // main game function
-func game() bool {
-	if showRules() {
+func (t *Terminal) game() bool {
+	if t.showRules() {
 		return true
 	}
-	// game on pause
-	pause := false
-	go timeControl(&pause)
-	go printSudo(&pause)
+	board := t.board
+	// timer and render loop stop when the game is left
+	session := newSession(board, t.console, t.resized)
+	defer session.Stop()
+	// time is counted only while the board is played
+	clock := board.Clock()
+	clock.Start()
+	defer clock.Stop()
+	// numbers are entered as values or pencil marks
+	noteMode := NoteOff
+	// rows and columns are labeled to name the boxes
+	labels := LabelsOff
+	// message shown until the next key and the message on the screen
+	message, shown := "", ""
 	// game loop
 	for {
 		// if timer has ended or board is finished
 		if board.IsComplete() || board.TimeEnd() {
 			break
 		}
+		// full board is not finished only when some numbers are wrong
+		if message == "" && board.IsFilled() {
+			message = "Every box is filled, but some numbers are wrong"
+		}
+		if message != shown {
+			// update so the Display is true
+			board.Move(0, 0)
+		}
+		// draw only when there is info to display
+		if board.Display() {
+			drawBoard(session, noteMode, labels, message)
+			shown = message
+		}
 
-		if keyBool {
-			keyBool = false
-			// move with arrows
-			if key == keyboard.KeyArrowUp {
-				board.Move(0, -1)
-			} else if key == keyboard.KeyArrowDown {
-				board.Move(0, 1)
-			} else if key == keyboard.KeyArrowRight {
-				board.Move(1, 0)
-			} else if key == keyboard.KeyArrowLeft {
-				board.Move(-1, 0)
-			} else if key == keyboard.KeyEsc { // pause game
-				pause = true
-				ClearConsole()
-				blueFont.Println("Press Esc second time to pause or BackSpace to get back to menu or Ctrl+S to save game(any other to continue)")
-				for {
-					if keyBool {
-						keyBool = false
-						if key == keyboard.KeyEsc {
-							return false
-						} else if key == keyboard.KeyBackspace {
-							return true
-						} else if key == keyboard.KeyCtrlS {
-							_ = board.SaveGame()
-							return false
-						} else {
-							break
-						}
+		// wait for a key or a tick of the timer
+		var event keyboard.KeyEvent
+		select {
+		case event = <-t.keys:
+		case <-session.ticks:
+			board.Tick()
+			continue
+		}
+		char, key := event.Rune, event.Key
+		message = ""
+		// move with arrows, cursor wraps around the edges
+		if key == keyboard.KeyArrowUp {
+			step(board, -1, 0)
+		} else if key == keyboard.KeyArrowDown {
+			step(board, 1, 0)
+		} else if key == keyboard.KeyArrowRight {
+			step(board, 0, 1)
+		} else if key == keyboard.KeyArrowLeft {
+			step(board, 0, -1)
+		} else if key == keyboard.KeyTab || key == keyboard.KeyCtrlB || isShiftTab(char, key) { // next or previous empty box
+			nextEmpty(board, key != keyboard.KeyTab)
+		} else if key == keyboard.KeyPgdn || key == keyboard.KeyPgup { // next or previous nonet
+			jumpNonet(board, key == keyboard.KeyPgup)
+		} else if key == keyboard.KeyHome || key == keyboard.KeyEnd { // edges of the row
+			rowEdge(board, key == keyboard.KeyEnd)
+		} else if key == keyboard.KeyCtrlG { // go to the box
+			session.Release()
+			text, ok := t.readLine("Go to the box(like r5c7 or G5, Esc to cancel):")
+			if ok {
+				if err := placeAt(board, text); err != nil {
+					message = err.Error()
+				}
+			}
+			board.Move(0, 0)
+		} else if char == ':' { // run command typed by the player
+			session.Release()
+			text, ok := t.readLine("Type a command(help lists all, Esc to cancel):")
+			if fields := strings.Fields(text); ok && len(fields) > 0 {
+				var out strings.Builder
+				// stopped clock keeps the time played in saves
+				clock.Stop()
+				err := runBoardCommand(board, fields, &out)
+				clock.Start()
+				if err == errQuit {
+					return true
+				} else if err != nil {
+					message = "error: " + err.Error()
+				} else {
+					message = strings.TrimRight(out.String(), "\n")
+				}
+			}
+			board.Move(0, 0)
+		} else if char == '?' { // list all keys, the board is hidden so the clock stops
+			clock.Stop()
+			session.Release()
+			t.showKeys()
+			clock.Start()
+			board.Move(0, 0)
+		} else if key == keyboard.KeyEsc { // pause game
+			clock.Stop()
+			session.Release()
+			var pause strings.Builder
+			blueFont.Fprintln(&pause, "Press Esc second time to pause or BackSpace to get back to menu")
+			blueFont.Fprintln(&pause, "Ctrl+S - save game, Ctrl+E - save game without answers")
+			blueFont.Fprintln(&pause, "Ctrl+X - export board, Ctrl+P - get share code")
+			blueFont.Fprintln(&pause, "Any other key to continue")
+			t.show(pause.String())
+			for {
+				_, key := t.waitKey()
+				if key == keyboard.KeyEsc {
+					return false
+				} else if key == keyboard.KeyBackspace {
+					return true
+				} else if key == keyboard.KeyCtrlS || key == keyboard.KeyCtrlE {
+					var err error
+					if key == keyboard.KeyCtrlS {
+						_, err = board.SaveGame("")
+					} else {
+						_, err = board.SaveSecure("")
 					}
+					if err == nil {
+						return false
+					}
+					// keep playing, so the progress is not lost
+					t.showError("Could not save the game", err, "continue the game")
+					break
+				} else if key == keyboard.KeyCtrlX {
+					t.exportMenu()
+					break
+				} else if key == keyboard.KeyCtrlP {
+					t.showShareCode()
+					break
+				} else {
+					break
 				}
-				pause = false
-				board.Move(0, 0)
-			} else if key == keyboard.KeyCtrlZ { // undo move
-				board.Undo()
-			} else if key == keyboard.KeyCtrlY { // redo move
-				board.Redo()
-			} else if key == keyboard.KeyCtrlR { // reveal random element
-				board.RevealRandom()
-			} else if '1' <= char && char <= '9' { // enter 0 to 9
-				board.Enter(int(char - '0'))
-			} else if 'a' <= char && char <= 'z' {
-				board.Enter(int(char - 'a' + 10))
-			} else if 'A' <= char && char <= 'Z' {
-				board.Enter(int(char - 'A' + 10))
+			}
+			clock.Start()
+			board.Move(0, 0)
+		} else if key == keyboard.KeyCtrlZ { // undo move
+			board.Undo()
+		} else if key == keyboard.KeyCtrlY { // redo move
+			board.Redo()
+		} else if key == keyboard.KeyCtrlR { // reveal random element
+			board.RevealRandom()
+		} else if key == keyboard.KeyCtrlA { // fill candidates of every empty box
+			board.AutoNotes()
+		} else if key == keyboard.KeyCtrlL { // switch cleanup of marks
+			board.SetAutoCleanup(!board.View().Cleanup)
+		} else if key == keyboard.KeyCtrlF { // switch how mistakes are shown
+			board.SetCheckMode((board.View().CheckMode + 1) % len(checkModes))
+		} else if key == keyboard.KeyCtrlK || key == keyboard.KeyCtrlW { // check board for time penalty
+			show := key == keyboard.KeyCtrlW
+			wrong := len(board.Check(show))
+			message = fmt.Sprintf("Wrong numbers: %d, %d seconds added", wrong, int(checkCost(show).Seconds()))
+		} else if key == keyboard.KeyCtrlN { // switch labels of rows and columns
+			labels = (labels + 1) % len(labelSchemes)
+			// update so the Display is true
+			board.Move(0, 0)
+		} else if key == keyboard.KeySpace { // switch between values, center and corner marks
+			noteMode = (noteMode + 1) % len(noteModes)
+			// update so the Display is true
+			board.Move(0, 0)
+		} else if key == keyboard.KeyDelete || key == keyboard.KeyBackspace || key == keyboard.KeyBackspace2 || (char != 0 && parseCell(char) == 0) { // clear the box
+			board.Erase()
+		} else if val := parseCell(char); val > 0 { // enter 1 to 9 and letters from A
+			if noteMode == NoteOff {
+				board.Enter(val)
+			} else {
+				board.Note(val, noteMode == NoteCorner)
 			}
 		}
 	}
-	pause = true
-	ClearConsole()
-	board.Print()
+	clock.Stop()
+	session.Stop()
+	var frame strings.Builder
+	board.HideCursor()
+	board.Print(&TerminalRenderer{&frame, labels})
 
 	// decide whether the user lost or won
 	if board.IsComplete() {
-		greenFont.Println("\nCongrats on finishing sudoku!")
+		greenFont.Fprintln(&frame, "\nCongrats on finishing sudoku!")
 	} else {
-		redFont.Println("\nSorry, you lost on time. Good luck next time ;)")
+		redFont.Fprintln(&frame, "\nSorry, you lost on time. Good luck next time ;)")
 	}
-	blueFont.Println("Press Backspace to get back to menu, Esc to pause")
+	blueFont.Fprintln(&frame, "Press Backspace to get back to menu, Esc to pause")
+	t.show(frame.String())
 
 	for {
-		if keyBool {
-			if key == keyboard.KeyEsc {
-				return false
-			} else if key == keyboard.KeyBackspace {
-				return true
-			}
+		_, key := t.waitKey()
+		if key == keyboard.KeyEsc {
+			return false
+		} else if key == keyboard.KeyBackspace {
+			return true
 		}
 	}
 }
 
 // show board rule
-func showRules() bool {
-	ClearConsole()
-	blueFont.Println(board.Rules())
-	blueFont.Println("Press Enter to continue. Backspace to return to menu")
+func (t *Terminal) showRules() bool {
+	var frame strings.Builder
+	blueFont.Fprintln(&frame, t.board.Rules())
+	blueFont.Fprintln(&frame, "Press Enter to continue. Backspace to return to menu")
+	t.show(frame.String())
 	for {
-		if keyBool {
-			keyBool = false
-			if key == keyboard.KeyEnter {
-				return false
-			} else if key == keyboard.KeyBackspace {
-				return true
-			}
+		_, key := t.waitKey()
+		if key == keyboard.KeyEnter {
+			return false
+		} else if key == keyboard.KeyBackspace {
+			return true
 		}
 	}
 }
-
-// game board
-var board SudokuBoard
@@ -1,59 +1,531 @@
 package main
 
 import (
+	"crypto/sha256"
 	"encoding/gob"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
 	"os"
 	"path/filepath"
 	"strings"
+	"time"
 )
 
-// load game from file
-func loadGame() bool {
+// current version of the save files
+const saveVersion = 2
+
+// errors returned when saving or loading the game
+var (
+	ErrSaveNotFound   = errors.New("saved game not found")
+	ErrSaveCorrupt    = errors.New("saved game is corrupt")
+	ErrSaveVersion    = errors.New("saved game version is not supported")
+	ErrSavePermission = errors.New("permission denied")
+	ErrSaveName       = errors.New("save name must not contain a path")
+)
+
+// defaultSaves are names used when the save is not named, a new default save replaces the others
+var defaultSaves = []string{"basic.sudo", "diagonal.sudo", "two.sudo", "basic-secure.sudo", "diagonal-secure.sudo", "two-secure.sudo"}
+
+// SaveError to describe failed save or load of the game
+type SaveError struct {
+	Op   string // operation that failed (save or load)
+	Path string // path of the save file
+	Kind error  // one of ErrSave errors, nil if the kind is unknown
+	Err  error  // underlying error
+}
+
+func (e *SaveError) Error() string {
+	msg := e.Op
+	if e.Path != "" {
+		msg += " " + filepath.Base(e.Path)
+	}
+	if e.Kind != nil {
+		msg += ": " + e.Kind.Error()
+	}
+	if e.Err != nil {
+		msg += ": " + e.Err.Error()
+	}
+	return msg
+}
+
+// Unwrap allows errors.Is to match both kind and underlying error
+func (e *SaveError) Unwrap() []error {
+	return []error{e.Kind, e.Err}
+}
+
+// saveError to wrap err, kind is guessed from err if not given
+func saveError(op, path string, kind, err error) error {
+	if kind == nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			kind = ErrSaveNotFound
+		} else if errors.Is(err, fs.ErrPermission) {
+			kind = ErrSavePermission
+		}
+	}
+	return &SaveError{op, path, kind, err}
+}
+
+// saveHeader is written at the start of every save file
+type saveHeader struct {
+	Magic   string // always "SudokuGo"
+	Version int    // version of the save
+	Kind    string // board type (basic, diagonal or two)
+	Secure  bool   // whether the answers are omitted
+}
+
+// load game from the most recent default save in current directory, named saves are loaded by name
+func loadGame() (SudokuBoard, error) {
 	// get current directory
 	currentDir, err := os.Getwd()
 	if err != nil {
-		return false
+		return nil, saveError("load", "", nil, err)
 	}
 
-	// find all .sudo files in current directory
-	files, err := filepath.Glob(filepath.Join(currentDir, "*.sudo"))
-	if err != nil {
-		return false
+	// find default saves in current directory
+	var files []string
+	for _, name := range defaultSaves {
+		path := filepath.Join(currentDir, name)
+		if _, err := os.Stat(path); err == nil {
+			files = append(files, path)
+		}
+	}
+	if len(files) == 0 {
+		return nil, saveError("load", "", ErrSaveNotFound, nil)
 	}
 
-	// if there is not 1 .sudo file - something is wrong
-	if len(files) != 1 {
-		return false
+	// there should be only 1 file, but choose the newest just in case
+	latest, latestTime := "", time.Time{}
+	for _, name := range files {
+		info, err := os.Stat(name)
+		if err == nil && info.ModTime().After(latestTime) {
+			latest, latestTime = name, info.ModTime()
+		}
+	}
+	if latest == "" {
+		latest = files[0]
 	}
+	return loadGameFile(latest)
+}
+
+// load game from file at path
+func loadGameFile(path string) (SudokuBoard, error) {
 	// open file for read
-	file, err := os.Open(files[0])
+	file, err := os.Open(path)
 	if err != nil {
-		return false
+		return nil, saveError("load", path, nil, err)
 	}
 	defer file.Close()
 
+	var header saveHeader
+	var loaded SudokuBoard
 	decoder := gob.NewDecoder(file)
+	if decoder.Decode(&header) != nil {
+		// saves before versioning had no header and stored the kind in file name
+		loaded, err = loadLegacy(path)
+	} else if header.Magic != "SudokuGo" {
+		err = saveError("load", path, ErrSaveCorrupt, nil)
+	} else if header.Version > saveVersion || header.Version < 1 {
+		err = saveError("load", path, ErrSaveVersion, fmt.Errorf("version %d", header.Version))
+	} else {
+		loaded, err = decodeBoard(decoder, path, header)
+	}
+	if err == nil && header.Version < 2 {
+		migrateClock(loaded)
+	}
+	if err == nil {
+		migrateGivens(loaded)
+	}
+	if err != nil {
+		return nil, err
+	}
 
-	if strings.HasSuffix(files[0], "basic.sudo") {
+	// update so the Display is true
+	loaded.Move(0, 0)
+	return loaded, nil
+}
+
+// migrateClock to create clocks from whole seconds stored in saves of version 1 and older
+func migrateClock(board SudokuBoard) {
+	_, boards := shareBoards(board)
+	for _, basic := range boards {
+		basic.Time = clockFromSeconds(basic.TimeLeft, basic.Elapsed)
+	}
+}
+
+// migrateGivens to mark givens of saves made before they were stored
+func migrateGivens(board SudokuBoard) {
+	_, boards := shareBoards(board)
+	for _, basic := range boards {
+		if basic.Givens == nil {
+			basic.markGivens(basic.Puzzle())
+		}
+	}
+}
+
+// decodeBoard to decode board of the type written in header
+func decodeBoard(decoder *gob.Decoder, path string, header saveHeader) (SudokuBoard, error) {
+	corrupt := func(err error) (SudokuBoard, error) {
+		return nil, saveError("load", path, ErrSaveCorrupt, err)
+	}
+	if header.Secure {
+		loaded, err := loadSecure(decoder, header.Kind)
+		if err != nil {
+			return corrupt(err)
+		}
+		return loaded, nil
+	}
+
+	switch header.Kind {
+	case "basic":
 		var bas BasicSudoku
-		if err = decoder.Decode(&bas); err != nil {
-			return false
+		if err := decoder.Decode(&bas); err != nil {
+			return corrupt(err)
 		}
-		board = &bas
-	} else if strings.HasSuffix(files[0], "diagonal.sudo") {
+		if !bas.valid() || !bas.cursorInside() {
+			return corrupt(nil)
+		}
+		return &bas, nil
+	case "diagonal":
 		var bas DiagonalSudoku
-		if err = decoder.Decode(&bas); err != nil {
-			return false
+		if err := decoder.Decode(&bas); err != nil {
+			return corrupt(err)
+		}
+		if !bas.valid() || !bas.cursorInside() {
+			return corrupt(nil)
 		}
-		board = &bas
-	} else if strings.HasSuffix(files[0], "two.sudo") {
+		return &bas, nil
+	case "two":
 		var bas TwoDoku
-		if err = decoder.Decode(&bas); err != nil {
+		if err := decoder.Decode(&bas); err != nil {
+			return corrupt(err)
+		}
+		if !bas.valid() {
+			return corrupt(nil)
+		}
+		return &bas, nil
+	}
+	return nil, saveError("load", path, ErrSaveVersion, fmt.Errorf("unknown board %q", header.Kind))
+}
+
+// loadLegacy to load save without header
+func loadLegacy(path string) (SudokuBoard, error) {
+	name := filepath.Base(path)
+	kind := strings.TrimSuffix(name, ".sudo")
+	if kind != "basic" && kind != "diagonal" && kind != "two" {
+		return nil, saveError("load", path, ErrSaveCorrupt, nil)
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, saveError("load", path, nil, err)
+	}
+	defer file.Close()
+	return decodeBoard(gob.NewDecoder(file), path, saveHeader{"SudokuGo", 0, kind, false})
+}
+
+// valid returns whether the decoded board has consistent sizes and numbers that fit the board
+func (s *BasicSudoku) valid() bool {
+	value := func(val int) bool {
+		return val >= 0 && val <= s.Size
+	}
+	marks := func(notes NoteMarks) bool {
+		limit := 1 << (s.Size + 1)
+		return notes.Center >= 0 && notes.Center < limit && notes.Corner >= 0 && notes.Corner < limit
+	}
+	if s.Size <= 0 || len(s.Board) != s.Size || len(s.BoardShow) != s.Size || s.NonetSize.Xpos*s.NonetSize.Ypos != s.Size {
+		return false
+	}
+	for i := 0; i < s.Size; i++ {
+		if len(s.Board[i]) != s.Size || len(s.BoardShow[i]) != s.Size {
+			return false
+		}
+		for j := 0; j < s.Size; j++ {
+			if !value(s.Board[i][j]) || !value(s.BoardShow[i][j]) {
+				return false
+			}
+		}
+	}
+	if (s.Notes != nil && len(s.Notes) != s.Size) || (s.Givens != nil && len(s.Givens) != s.Size) {
+		return false
+	}
+	for _, line := range s.Notes {
+		if len(line) != s.Size {
+			return false
+		}
+		for _, notes := range line {
+			if !marks(notes) {
+				return false
+			}
+		}
+	}
+	for _, line := range s.Givens {
+		if len(line) != s.Size {
+			return false
+		}
+	}
+	if s.CursorPos.Xpos < -1 || s.CursorPos.Ypos < -1 || s.CursorPos.Xpos >= s.Size || s.CursorPos.Ypos >= s.Size || s.CurrentAction < 0 || s.CurrentAction > len(s.Actions) {
+		return false
+	}
+	if s.CheckMode < 0 || s.CheckMode >= len(checkModes) {
+		return false
+	}
+	for _, action := range s.Actions {
+		if action.Pos.Xpos < 0 || action.Pos.Ypos < 0 || action.Pos.Xpos >= s.Size || action.Pos.Ypos >= s.Size {
+			return false
+		}
+		if !value(action.OldVal) || !value(action.NewVal) || !marks(action.OldNotes) || !marks(action.NewNotes) {
 			return false
 		}
-		board = &bas
 	}
-	// update so the Display is true
-	board.Move(0, 0)
 	return true
 }
+func (s *TwoDoku) valid() bool {
+	return s.BoardMain.valid() && s.BoardAdd.valid() && (s.BoardMain.cursorInside() || s.BoardAdd.cursorInside()) &&
+		s.CurrentAction >= 0 && s.CurrentAction <= len(s.Actions)
+}
+
+// cursorInside returns whether the cursor is on the board, only boards of TwoDoku may have it outside
+func (s *BasicSudoku) cursorInside() bool {
+	return s.CursorPos.Xpos >= 0 && s.CursorPos.Ypos >= 0 && s.CursorPos.Xpos < s.Size && s.CursorPos.Ypos < s.Size
+}
+
+// SecureSave to store game state without the answers
+type SecureSave struct {
+	Givens        [][]int       // numbers given at the start of the game
+	Entries       [][]int       // board shown to user, including player entries
+	SolutionHash  string        // hash of the answers to verify the solution after load
+	CursorPos     Vector2       // current player position
+	Notes         [][]NoteMarks // pencil marks of every box
+	AutoCleanup   bool          // entered numbers are removed from pencil marks of peers
+	CheckMode     int           // how mistakes are shown, answers, rules or blind
+	Seed          int64         // random seed the puzzle was generated with, 0 if it was not generated
+	Actions       []Change      // player moves
+	CurrentAction int           // current move
+	Time          GameClock     // play time
+	TimeLeft      int           // seconds left on timer in saves of version 1, -1 if there was no timer
+	Elapsed       int           // seconds played in saves of version 1
+}
+
+// SecureTwoSave to store TwoDoku game state without the answers
+type SecureTwoSave struct {
+	BoardMain     SecureSave     // first board
+	BoardAdd      SecureSave     // second board
+	Actions       []DoubleChange // player moves
+	CurrentAction int            // current move
+}
+
+// solutionHash returns hex encoded sha256 hash of the board
+func solutionHash(board [][]int) string {
+	hash := sha256.New()
+	for _, line := range board {
+		for _, element := range line {
+			_, _ = fmt.Fprintf(hash, "%d,", element)
+		}
+	}
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// Puzzle returns board as it was at the start of the game
+func (s *BasicSudoku) Puzzle() [][]int {
+	puzzle := make([][]int, s.Size)
+	for i := range puzzle {
+		puzzle[i] = make([]int, s.Size)
+		for j := range puzzle[i] {
+			if s.Givens == nil || s.Givens[i][j] {
+				puzzle[i][j] = s.BoardShow[i][j]
+			}
+		}
+	}
+	if s.Givens != nil {
+		return puzzle
+	}
+	// without givens mask the first move in each box remembers its initial value
+	for i := len(s.Actions) - 1; i >= 0; i-- {
+		puzzle[s.Actions[i].Pos.Xpos][s.Actions[i].Pos.Ypos] = s.Actions[i].OldVal
+	}
+	return puzzle
+}
+
+// secureState to create game state without the answers
+func (s *BasicSudoku) secureState() SecureSave {
+	entries := make([][]int, s.Size)
+	for i := range entries {
+		entries[i] = make([]int, s.Size)
+		copy(entries[i], s.BoardShow[i])
+	}
+	return SecureSave{
+		Givens:        s.Puzzle(),
+		Entries:       entries,
+		SolutionHash:  solutionHash(s.Board),
+		CursorPos:     s.CursorPos,
+		Notes:         s.Notes,
+		AutoCleanup:   s.AutoCleanup,
+		CheckMode:     s.CheckMode,
+		Seed:          s.Seed,
+		Actions:       s.Actions,
+		CurrentAction: s.CurrentAction,
+		Time:          s.Time,
+	}
+}
+
+// restoreState to restore game state with givens as the only numbers in the answers board
+func (s *BasicSudoku) restoreState(save SecureSave) bool {
+	size := len(save.Givens)
+	if size == 0 || len(save.Entries) != size {
+		return false
+	}
+	s.PreInit(size, -1)
+	for i := 0; i < size; i++ {
+		if len(save.Givens[i]) != size || len(save.Entries[i]) != size {
+			return false
+		}
+		copy(s.Board[i], save.Givens[i])
+		copy(s.BoardShow[i], save.Entries[i])
+	}
+	s.Time = save.Time
+	s.TimeLeft = save.TimeLeft
+	s.Elapsed = save.Elapsed
+	s.CursorPos = save.CursorPos
+	s.markGivens(save.Givens)
+	s.Notes = save.Notes
+	s.AutoCleanup = save.AutoCleanup
+	s.CheckMode = save.CheckMode
+	s.Seed = save.Seed
+	s.Actions = save.Actions
+	s.CurrentAction = save.CurrentAction
+	return s.valid()
+}
+
+// saveName returns file name of the save called name, standard name if it is empty
+func saveName(name, standard string) (string, error) {
+	if name == "" {
+		return standard, nil
+	}
+	if strings.ContainsAny(name, `/\`) || strings.ContainsRune(name, os.PathSeparator) || strings.Contains(name, "..") {
+		return "", saveError("save", "", ErrSaveName, fmt.Errorf("%q", name))
+	}
+	if !strings.HasSuffix(name, ".sudo") {
+		name += ".sudo"
+	}
+	return name, nil
+}
+
+// writeSave to write data in the save called name and return its path, unnamed save replaces old default saves
+func writeSave(name, standard string, header saveHeader, data any) (string, error) {
+	replace := name == ""
+	name, err := saveName(name, standard)
+	if err != nil {
+		return "", err
+	}
+	header.Magic = "SudokuGo"
+	header.Version = saveVersion
+
+	// create file for save
+	file, err := os.Create(name)
+	if err != nil {
+		return "", saveError("save", name, nil, err)
+	}
+
+	// Create an encoder and send header and struct for encoding
+	enc := gob.NewEncoder(file)
+	err = enc.Encode(header)
+	if err == nil {
+		err = enc.Encode(data)
+	}
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return "", saveError("save", name, nil, err)
+	}
+
+	// remove old default saves only after the new one was written, named saves are kept
+	if replace {
+		if err = ClearFiles(name); err != nil {
+			return "", saveError("save", name, nil, err)
+		}
+	}
+	if path, err := filepath.Abs(name); err == nil {
+		return path, nil
+	}
+	return name, nil
+}
+
+// SaveSecure to save game state without the answers in .sudo file
+func (s *BasicSudoku) SaveSecure(name string) (string, error) {
+	return writeSave(name, "basic-secure.sudo", saveHeader{Kind: "basic", Secure: true}, s.secureState())
+}
+func (s *DiagonalSudoku) SaveSecure(name string) (string, error) {
+	return writeSave(name, "diagonal-secure.sudo", saveHeader{Kind: "diagonal", Secure: true}, s.secureState())
+}
+func (s *TwoDoku) SaveSecure(name string) (string, error) {
+	return writeSave(name, "two-secure.sudo", saveHeader{Kind: "two", Secure: true}, SecureTwoSave{s.BoardMain.secureState(), s.BoardAdd.secureState(), s.Actions, s.CurrentAction})
+}
+
+// loadSecure to restore the board and solve it again from the givens
+func loadSecure(decoder *gob.Decoder, kind string) (SudokuBoard, error) {
+	errUnsolvable := errors.New("givens do not match the saved solution")
+	if kind == "two" {
+		var save SecureTwoSave
+		if err := decoder.Decode(&save); err != nil {
+			return nil, err
+		}
+		var two TwoDoku
+		if !two.BoardMain.restoreState(save.BoardMain) || !two.BoardAdd.restoreState(save.BoardAdd) || two.BoardMain.Size != 9 || two.BoardAdd.Size != 9 {
+			return nil, errors.New("invalid board size")
+		}
+		two.Actions = save.Actions
+		two.CurrentAction = save.CurrentAction
+		if !two.valid() {
+			return nil, errors.New("invalid history of moves")
+		}
+		// solve main board first, as it defines the adjacent nonet
+		if !two.BoardMain.FillSudoku(0, nil) {
+			return nil, errUnsolvable
+		}
+		for i := 0; i < 3; i++ {
+			for j := 0; j < 3; j++ {
+				two.BoardAdd.Board[i][j] = two.BoardMain.Board[i+6][j+6]
+			}
+		}
+		if !two.BoardAdd.FillSudoku(0, nil) {
+			return nil, errUnsolvable
+		}
+		// make sure solution is the same as before save
+		if solutionHash(two.BoardMain.Board) != save.BoardMain.SolutionHash || solutionHash(two.BoardAdd.Board) != save.BoardAdd.SolutionHash {
+			return nil, errUnsolvable
+		}
+		return &two, nil
+	}
+
+	var save SecureSave
+	if err := decoder.Decode(&save); err != nil {
+		return nil, err
+	}
+	var solved bool
+	var ret SudokuBoard
+	var basic *BasicSudoku
+	if kind == "diagonal" {
+		diagonal := &DiagonalSudoku{}
+		if !diagonal.restoreState(save) || !diagonal.cursorInside() {
+			return nil, errors.New("invalid board size")
+		}
+		solved = diagonal.FillSudoku(0, nil)
+		basic, ret = &diagonal.BasicSudoku, diagonal
+	} else if kind == "basic" {
+		basic = &BasicSudoku{}
+		if !basic.restoreState(save) || !basic.cursorInside() {
+			return nil, errors.New("invalid board size")
+		}
+		solved = basic.FillSudoku(0, nil)
+		ret = basic
+	} else {
+		return nil, fmt.Errorf("unknown board %q", kind)
+	}
+	// make sure solution is the same as before save
+	if !solved || solutionHash(basic.Board) != save.SolutionHash {
+		return nil, errUnsolvable
+	}
+	return ret, nil
+}
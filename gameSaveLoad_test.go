@@ -0,0 +1,237 @@
+package main
+
+import (
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"testing"
+)
+
+// inTempDir to run the test in empty directory, saves are written to the current one
+func inTempDir(t *testing.T) {
+	t.Helper()
+	dir, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(t.TempDir()); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = os.Chdir(dir) })
+}
+
+// playedBoard returns generated board with a correct and a wrong entry and pencil marks
+func playedBoard(t *testing.T, variant string, size int) SudokuBoard {
+	t.Helper()
+	board := newBoard(variant, size, 1, -1, 5)
+	_, boards := shareBoards(board)
+	pos := firstEmpty(boards[0])
+	board.Place(pos)
+	board.Enter(boards[0].Board[pos.Xpos][pos.Ypos]%size + 1)
+	board.RevealRandom()
+	board.Place(firstEmpty(boards[0]))
+	board.Note(1, false)
+	board.Note(2, true)
+	return board
+}
+
+// notesOf returns pencil marks of every box of the board
+func notesOf(board SudokuBoard) [][]NoteMarks {
+	var notes [][]NoteMarks
+	for _, line := range board.View().Cells {
+		var marks []NoteMarks
+		for _, cell := range line {
+			marks = append(marks, cell.Notes)
+		}
+		notes = append(notes, marks)
+	}
+	return notes
+}
+
+func TestSecureSaveRoundTrip(t *testing.T) {
+	tests := []struct {
+		variant string
+		size    int
+	}{
+		{"square", 4},
+		{"square", 9},
+		{"diagonal", 9},
+		{"twodoku", 9},
+	}
+	for _, test := range tests {
+		t.Run(fmt.Sprintf("%s %d", test.variant, test.size), func(t *testing.T) {
+			inTempDir(t)
+			board := playedBoard(t, test.variant, test.size)
+			if _, err := board.SaveSecure(""); err != nil {
+				t.Fatalf("SaveSecure returned %v", err)
+			}
+			loaded, err := loadGame()
+			if err != nil {
+				t.Fatalf("loadGame returned %v", err)
+			}
+
+			for _, content := range []int{ExportGivens, ExportProgress, ExportSolution} {
+				if got, want := loaded.Export(content, FormatLine), board.Export(content, FormatLine); got != want {
+					t.Errorf("export %s = %q, want %q", exportContents[content], got, want)
+				}
+			}
+			if got, want := loaded.Cursor(), board.Cursor(); got != want {
+				t.Errorf("cursor = %v, want %v", got, want)
+			}
+			if got, want := notesOf(loaded), notesOf(board); !reflect.DeepEqual(got, want) {
+				t.Errorf("pencil marks = %v, want %v", got, want)
+			}
+			// history is saved too
+			loaded.Undo()
+			board.Undo()
+			if got, want := loaded.Export(ExportProgress, FormatLine), board.Export(ExportProgress, FormatLine); got != want {
+				t.Errorf("export after undo = %q, want %q", got, want)
+			}
+		})
+	}
+}
+
+func TestSecureSaveTamperedHash(t *testing.T) {
+	tests := []struct {
+		variant string
+		kind    string
+	}{
+		{"square", "basic"},
+		{"diagonal", "diagonal"},
+		{"twodoku", "two"},
+	}
+	for _, test := range tests {
+		t.Run(test.variant, func(t *testing.T) {
+			inTempDir(t)
+			board := playedBoard(t, test.variant, 9)
+			// the same save with the right hash loads
+			path, err := board.SaveSecure("intact")
+			if err != nil {
+				t.Fatalf("SaveSecure returned %v", err)
+			}
+			if _, err := loadGameFile(path); err != nil {
+				t.Fatalf("loadGameFile of intact save returned %v", err)
+			}
+
+			var data any
+			if two, ok := board.(*TwoDoku); ok {
+				save := SecureTwoSave{two.BoardMain.secureState(), two.BoardAdd.secureState(), two.Actions, two.CurrentAction}
+				save.BoardAdd.SolutionHash = solutionHash(two.BoardMain.Board)
+				data = save
+			} else {
+				_, boards := shareBoards(board)
+				save := boards[0].secureState()
+				save.SolutionHash = solutionHash(boards[0].BoardShow)
+				data = save
+			}
+			path, err = writeSave("tampered", "", saveHeader{Kind: test.kind, Secure: true}, data)
+			if err != nil {
+				t.Fatalf("writeSave returned %v", err)
+			}
+			if loaded, err := loadGameFile(path); !errors.Is(err, ErrSaveCorrupt) {
+				t.Errorf("loadGameFile = %v, %v, want ErrSaveCorrupt", loaded, err)
+			}
+		})
+	}
+}
+
+// writeGob to write values to file at path one after another
+func writeGob(t *testing.T, path string, values ...any) {
+	t.Helper()
+	file, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	enc := gob.NewEncoder(file)
+	for _, value := range values {
+		if err := enc.Encode(value); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestLoadLegacySave(t *testing.T) {
+	tests := []struct {
+		name     string
+		header   bool // version 1 save with header, older saves have none
+		timeLeft int
+		elapsed  int
+	}{
+		{"version 1 with timer", true, 100, 20},
+		{"version 1 without timer", true, -1, 35},
+		{"without header", false, -1, 7},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			inTempDir(t)
+			board := playedBoard(t, "square", 9).(*BasicSudoku)
+			// old saves stored seconds and no givens
+			legacy := *board
+			legacy.Givens = nil
+			legacy.Time = GameClock{}
+			legacy.TimeLeft, legacy.Elapsed = test.timeLeft, test.elapsed
+			if test.header {
+				writeGob(t, "basic.sudo", saveHeader{"SudokuGo", 1, "basic", false}, &legacy)
+			} else {
+				writeGob(t, "basic.sudo", &legacy)
+			}
+
+			loaded, err := loadGame()
+			if err != nil {
+				t.Fatalf("loadGame returned %v", err)
+			}
+			if played, left := loaded.Clock().Seconds(); played != test.elapsed || left != test.timeLeft {
+				t.Errorf("clock = %d played, %d left, want %d and %d", played, left, test.elapsed, test.timeLeft)
+			}
+			for _, content := range []int{ExportGivens, ExportProgress, ExportSolution} {
+				if got, want := loaded.Export(content, FormatLine), board.Export(content, FormatLine); got != want {
+					t.Errorf("export %s = %q, want %q", exportContents[content], got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestLoadRejectsCorruptSave(t *testing.T) {
+	tests := []struct {
+		name    string
+		variant string
+		secure  bool
+		corrupt func(board SudokuBoard)
+	}{
+		{"number above size", "square", false, func(board SudokuBoard) { board.(*BasicSudoku).BoardShow[0][0] = 10 }},
+		{"negative answer", "square", false, func(board SudokuBoard) { board.(*BasicSudoku).Board[0][0] = -1 }},
+		{"pencil mark above size", "square", false, func(board SudokuBoard) { board.(*BasicSudoku).Notes[0][0].Center = 1 << 10 }},
+		{"move with number above size", "square", false, func(board SudokuBoard) { board.(*BasicSudoku).Actions[0].NewVal = 12 }},
+		{"cursor off board", "diagonal", false, func(board SudokuBoard) { board.(*DiagonalSudoku).CursorPos = Vector2{-1, -1} }},
+		{"cursor past board", "square", true, func(board SudokuBoard) { board.(*BasicSudoku).CursorPos = Vector2{9, 0} }},
+		{"move outside history", "square", false, func(board SudokuBoard) { board.(*BasicSudoku).CurrentAction = 10 }},
+		{"TwoDoku move outside history", "twodoku", false, func(board SudokuBoard) { board.(*TwoDoku).CurrentAction = -1 }},
+		{"TwoDoku secure move outside history", "twodoku", true, func(board SudokuBoard) { board.(*TwoDoku).CurrentAction = 10 }},
+		{"TwoDoku cursor off both boards", "twodoku", false, func(board SudokuBoard) {
+			board.(*TwoDoku).BoardMain.CursorPos = Vector2{-1, -1}
+			board.(*TwoDoku).BoardAdd.CursorPos = Vector2{-1, -1}
+		}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			inTempDir(t)
+			board := playedBoard(t, test.variant, 9)
+			test.corrupt(board)
+			save := board.SaveGame
+			if test.secure {
+				save = board.SaveSecure
+			}
+			path, err := save("corrupt")
+			if err != nil {
+				t.Fatalf("save returned %v", err)
+			}
+			if loaded, err := loadGameFile(path); !errors.Is(err, ErrSaveCorrupt) {
+				t.Errorf("loadGameFile = %v, %v, want ErrSaveCorrupt", loaded, err)
+			}
+		})
+	}
+}
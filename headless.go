@@ -0,0 +1,101 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// returned by quit command
+var errQuit = errors.New("quit")
+
+// parse 1-based row and column to cursor position
+func parsePosition(row, col string) (Vector2, error) {
+	x, err := strconv.Atoi(row)
+	if err != nil {
+		return Vector2{}, fmt.Errorf("row %q is not a number", row)
+	}
+	y, err := strconv.Atoi(col)
+	if err != nil {
+		return Vector2{}, fmt.Errorf("column %q is not a number", col)
+	}
+	return Vector2{x - 1, y - 1}, nil
+}
+
+// parse box reference like r5c7, G5 or 5 7 to cursor position
+func parseReference(text string) (Vector2, error) {
+	fields := strings.Fields(strings.ToLower(text))
+	if len(fields) == 2 {
+		return parsePosition(fields[0], fields[1])
+	}
+	if len(fields) == 1 && strings.HasPrefix(fields[0], "r") && strings.Contains(fields[0], "c") {
+		row, col, _ := strings.Cut(fields[0][1:], "c")
+		return parsePosition(row, col)
+	}
+	// column letter and row number, as named with letter labels
+	if len(fields) == 1 && len(fields[0]) > 1 && 'a' <= fields[0][0] && fields[0][0] <= 'z' {
+		return parsePosition(fields[0][1:], fmt.Sprint(fields[0][0]-'a'+1))
+	}
+	return Vector2{}, fmt.Errorf("box %q is not like r5c7 or G5", text)
+}
+
+// parse value of the box, digits and letters from A for values over 9
+func parseValue(value string) (int, error) {
+	runes := []rune(value)
+	if len(runes) == 1 && parseCell(runes[0]) > 0 {
+		return parseCell(runes[0]), nil
+	}
+	if val, err := strconv.Atoi(value); err == nil && val > 0 {
+		return val, nil
+	}
+	return 0, fmt.Errorf("value %q is not valid", value)
+}
+
+// runHeadless to play the board with commands read line by line, returns exit code
+func runHeadless(board SudokuBoard, in io.Reader, out io.Writer) int {
+	// time is counted the same way as in the interactive game
+	clock := board.Clock()
+	clock.Start()
+	defer clock.Stop()
+	scanner := bufio.NewScanner(in)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		// skip empty lines and comments
+		if len(fields) == 0 || strings.HasPrefix(fields[0], "#") {
+			continue
+		}
+
+		// stopped clock keeps the time played in saves
+		clock.Stop()
+		err := runBoardCommand(board, fields, out)
+		clock.Start()
+		if err == errQuit {
+			return exitOk
+		} else if err != nil {
+			_, _ = fmt.Fprintln(out, "error:", err)
+			continue
+		}
+		if board.IsComplete() {
+			_, _ = fmt.Fprintln(out, "solved")
+			return exitOk
+		}
+		if board.TimeEnd() {
+			_, _ = fmt.Fprintln(out, "time is up")
+			return exitFailure
+		}
+		// full board is not solved only when some values are wrong
+		if board.IsFilled() {
+			_, _ = fmt.Fprintln(out, "filled, some values are wrong")
+			continue
+		}
+		_, _ = fmt.Fprintln(out, "ok")
+	}
+	if err := scanner.Err(); err != nil {
+		_, _ = fmt.Fprintln(out, "error:", err)
+	}
+	// input ended before the board was solved
+	return exitFailure
+}
@@ -1,29 +1,72 @@
 package main
 
 import (
+	"fmt"
 	"github.com/eiannone/keyboard"
+	"golang.org/x/term"
+	"os"
 )
 
 func main() {
+	// run command line commands if there are any
+	if len(os.Args) > 1 {
+		os.Exit(runCommand(os.Args[1:], os.Stdout, os.Stderr))
+	}
+	// without terminal moves are read from stdin
+	if !term.IsTerminal(int(os.Stdin.Fd())) {
+		os.Exit(runCommand([]string{"play", "--headless"}, os.Stdout, os.Stderr))
+	}
+	os.Exit(play(nil))
+}
+
+// Terminal is the interactive game, it owns the board, the keyboard and the console
+type Terminal struct {
+	board   SudokuBoard              // board being played
+	keys    <-chan keyboard.KeyEvent // key presses
+	console *ScreenBuffer            // screen of the game
+	resized chan struct{}            // notified when console window is resized
+	frame   string                   // menu shown on the console, drawn again on resize
+}
+
+// run interactive game, starting with the board if it is not nil, returns exit code
+func play(board SudokuBoard) int {
+	// start keyboard listening only when game is interactive
+	events, err := keyboard.GetKeys(10)
+	if err != nil {
+		_, _ = fmt.Fprintln(os.Stderr, "keyboard is not available:", err)
+		_, _ = fmt.Fprintln(os.Stderr, "use \"sudoku play --headless\" to play without terminal")
+		return exitFailure
+	}
 	// close keyboard output
 	defer keyboard.Close()
+	t := &Terminal{
+		board:   board,
+		keys:    events,
+		console: NewScreenBuffer(os.Stdout),
+		resized: make(chan struct{}, 1),
+	}
 	// enable cursor after program finish
-	defer EnableCursor()
-	// thread to wait for keys
-	go readKeys(&char, &key, &keyBool)
-	// disable console cursor
+	defer t.EnableCursor()
+	// prepare console and disable its cursor
+	t.InitConsole()
 	DisableCursor()
 
 	// program loop
 	contin := true
+	if board != nil {
+		// update so the Display is true
+		board.Move(0, 0)
+		contin = t.game()
+	}
 	for contin {
-		if menu() {
+		if t.menu() {
 			// enter game if user didn't exit
-			contin = game()
+			contin = t.game()
 		} else {
 			contin = false
 		}
 	}
-	ClearConsole()
+	t.ClearConsole()
 	blueFont.Print("\tThanks for choosing to play our Sudoku. May you have a blessed day :)")
+	return exitOk
 }
@@ -1,9 +1,12 @@
 package main
 
 import (
+	"errors"
 	"fmt"
 	"github.com/eiannone/keyboard"
 	"github.com/fatih/color"
+	"math/rand"
+	"path/filepath"
 	"strconv"
 	"strings"
 )
@@ -14,51 +17,54 @@ var blueFont *color.Color
 var greenFont *color.Color
 var redFont *color.Color
 var diagonalFont *color.Color
+var givenFont *color.Color
+var peerFont *color.Color
+var sameFont *color.Color
 
-// init keyboard listening and fonts
+// init fonts
 func init() {
-	err := keyboard.Open()
-	if err != nil {
-		panic(err)
-	}
-
 	blueFont = color.New(color.FgCyan)
 	purpleFont = color.New(color.FgMagenta)
 	greenFont = color.New(color.FgGreen)
 	redFont = color.New(color.FgRed)
 	diagonalFont = color.New(color.FgYellow)
+	givenFont = color.New(color.FgWhite, color.Bold)
+	peerFont = color.New(color.BgHiBlack)
+	sameFont = color.New(color.BgBlue)
 }
 
 // create menu and return true if succeeded, false if user exited
-func menu() bool {
+func (t *Terminal) menu() bool {
 	// start menu options with output
-	outputMenuStart := [4]string{"\tWelcome to Sudoku! (operate with Up and Down, then press Enter to confirm)\n", " New Game", " Load Game", " Exit"}
+	outputMenuStart := [6]string{"Welcome to Sudoku! (operate with Up and Down, then press Enter to confirm)\n", " New Game", " Load Game", " Import Puzzle", " Enter Share Code", " Exit"}
 
 	// initialise menu data
 	selected := 1
-	outputLimit := [2]int{1, 3}
+	outputLimit := [2]int{1, 5}
 
 	// function for drawing frame
 	Draw := func() {
-		ClearConsole()
+		var frame strings.Builder
 		for index, element := range outputMenuStart {
 			if index < outputLimit[0] {
-				blueFont.Println(element)
+				blueFont.Fprintln(&frame, element)
 			} else if selected == index {
-				purpleFont.Println("> " + element)
+				purpleFont.Fprintln(&frame, "> "+element)
 			} else {
-				fmt.Println(element)
+				_, _ = fmt.Fprintln(&frame, element)
 			}
 		}
+		t.show(frame.String())
 	}
 
-	// draw menu for the first time
-	Draw()
-
-	// iterate until option is chosen
+	// show menu until game is chosen or user exits
 	for {
-		if keyBool {
-			keyBool = false
+		// draw menu for the first time
+		Draw()
+
+		// iterate until option is chosen
+		for {
+			_, key := t.waitKey()
 			if key == keyboard.KeyArrowUp && selected > outputLimit[0] {
 				selected--
 			} else if key == keyboard.KeyArrowDown && selected < outputLimit[1] {
@@ -70,34 +76,127 @@ func menu() bool {
 			}
 			Draw()
 		}
-	}
 
-	// choose what to do Next
-	switch selected {
-	// new game
-	case 1:
-		if newGameMenu() {
-			blueFont.Println("Loading...")
-			return initGame()
-		} else {
+		// choose what to do Next
+		switch selected {
+		// new game
+		case 1:
+			if gameParam, ok := t.newGameMenu(); ok {
+				t.show(blueFont.Sprint("Loading..."))
+				return t.initGame(gameParam)
+			} else {
+				return false
+			}
+		// load old game
+		case 2:
+			loaded, err := loadGame()
+			if err == nil {
+				t.board = loaded
+				return true
+			}
+			t.showError("Could not load the game", err, "return to menu")
+		// import puzzle from file
+		case 3:
+			if t.importMenu() {
+				return true
+			}
+		// start game from share code
+		case 4:
+			code, ok := t.readLine("Paste share code and press Enter (Esc to return to menu)")
+			if !ok {
+				break
+			}
+			t.show(blueFont.Sprint("Loading..."))
+			shared, err := FromShareCode(code)
+			if err != nil {
+				t.showError("Could not start the game", err, "return to menu")
+				break
+			}
+			t.board = shared
+			return true
+		// exit option
+		case 5:
 			return false
+		// should be impossible, but just in case
+		default:
+			panic("How did you do it?!")
 		}
-	// load old game
-	case 2:
-		return loadGame()
-	// exit option
-	case 3:
-		return false
-	// should be impossible, but just in case
-	default:
-		panic("How did you do it?!")
+	}
+}
 
+// describe save, load or import error to the user
+func describeError(err error) string {
+	switch {
+	case errors.Is(err, ErrPuzzleFormat):
+		return "The file does not look like a sudoku puzzle."
+	case errors.Is(err, ErrPuzzleInvalid):
+		return "The puzzle breaks sudoku rules."
+	case errors.Is(err, ErrPuzzleUnsolvable):
+		return "The puzzle cannot be solved."
+	case errors.Is(err, ErrPuzzleNotUnique):
+		return "The puzzle has more than one solution."
+	case errors.Is(err, ErrShareCode):
+		return "The share code is mistyped or incomplete."
+	case errors.Is(err, ErrSaveNotFound):
+		return "There is no saved game in the current directory."
+	case errors.Is(err, ErrSaveCorrupt):
+		return "The saved game is damaged and cannot be restored."
+	case errors.Is(err, ErrSaveVersion):
+		return "The saved game was made by a different version of Sudoku."
+	case errors.Is(err, ErrSavePermission):
+		return "Sudoku is not allowed to access the save file."
+	case errors.Is(err, ErrSaveName):
+		return "The save name must not contain a folder."
 	}
-	return true
+	return "Something went wrong."
 }
 
-// store new game parameters
-var gameParam [4]int
+// read line of text typed by user, false if user cancelled with Esc
+func (t *Terminal) readLine(prompt string) (string, bool) {
+	var text []rune
+
+	// function for drawing frame
+	Draw := func() {
+		var frame strings.Builder
+		blueFont.Fprintln(&frame, prompt)
+		purpleFont.Fprint(&frame, "> ")
+		// long text like share codes goes on in the next lines
+		_, _ = fmt.Fprintln(&frame, wrapLines(string(text), menuWidth-2))
+		t.show(frame.String())
+	}
+
+	// draw input for the first time
+	Draw()
+
+	for {
+		char, key := t.waitKey()
+		if key == keyboard.KeyEnter {
+			return string(text), true
+		} else if key == keyboard.KeyEsc {
+			return "", false
+		} else if (key == keyboard.KeyBackspace || key == keyboard.KeyBackspace2) && len(text) > 0 {
+			text = text[:len(text)-1]
+		} else if key == keyboard.KeySpace {
+			text = append(text, ' ')
+		} else if char != 0 {
+			text = append(text, char)
+		} else {
+			continue
+		}
+		Draw()
+	}
+}
+
+// show error to the user and wait for any key
+func (t *Terminal) showError(title string, err error, next string) {
+	var frame strings.Builder
+	redFont.Fprintln(&frame, title)
+	redFont.Fprintln(&frame, describeError(err))
+	_, _ = fmt.Fprintln(&frame, wrapLines(err.Error(), menuWidth))
+	blueFont.Fprintln(&frame, "Press any key to "+next)
+	t.show(frame.String())
+	t.waitKey()
+}
 
 // options for new game
 var gameOptions = [][]string{
@@ -109,26 +208,27 @@ var gameOptions = [][]string{
 	{},
 }
 
-// init new game
-func newGameMenu() bool {
+// choose new game parameters, false if user exited
+func (t *Terminal) newGameMenu() ([4]int, bool) {
 	// start menu options with output
 	outputMenuOptions := [6]string{"Shape", "Size", "Difficulty", "Clock", "Play", "Exit"}
 
 	/*initialise menu data*/
 	selected := 4
 	outputLimit := [2]int{0, 5}
-	gameParam = [4]int{0, 1, 0, 0}
+	gameParam := [4]int{0, 1, 0, 0}
 
 	// function for drawing frame
 	Draw := func() {
-		ClearConsole()
-		blueFont.Println("Choose game options! (operate with arrows, then press Enter to confirm either Start or Exit)")
+		var frame strings.Builder
+		blueFont.Fprintln(&frame, "Choose game options!")
+		blueFont.Fprintln(&frame, "(operate with arrows, then press Enter to confirm either Play or Exit)")
 		for index, element := range outputMenuOptions {
 			// omit first info output
 			if selected == index {
-				purpleFont.Print("> " + element)
+				purpleFont.Fprint(&frame, "> "+element)
 			} else {
-				fmt.Print(element)
+				_, _ = fmt.Fprint(&frame, element)
 			}
 			tmpPos := index - outputLimit[0]
 			tmpLen := len(gameOptions[tmpPos])
@@ -136,13 +236,14 @@ func newGameMenu() bool {
 			if tmpLen > 0 {
 				// limit size choice for non Basic sudoku
 				if element == "Size" && gameParam[0] != 0 {
-					greenFont.Print(" < 9x9 >")
+					greenFont.Fprint(&frame, " < 9x9 >")
 				} else {
-					greenFont.Print(" < " + gameOptions[tmpPos][gameParam[tmpPos]] + " >")
+					greenFont.Fprint(&frame, " < "+gameOptions[tmpPos][gameParam[tmpPos]]+" >")
 				}
 			}
-			fmt.Println()
+			_, _ = fmt.Fprintln(&frame)
 		}
+		t.show(frame.String())
 	}
 
 	// draw menu for the first time
@@ -150,86 +251,335 @@ func newGameMenu() bool {
 
 	// iterate until option is chosen
 	for {
-		if keyBool {
-			keyBool = false
-			if key == keyboard.KeyArrowUp && selected > outputLimit[0] {
-				selected--
-			} else if key == keyboard.KeyArrowDown && selected < outputLimit[1] {
-				selected++
-			} else if key == keyboard.KeyArrowRight && !(gameParam[0] != 0 && selected == 1) {
-				// position in gameOptions and gameParam
-				tmpPos := selected - outputLimit[0]
-				// number of scroll options
-				tmpLen := len(gameOptions[tmpPos])
-
-				// if there are option
-				if tmpLen > 0 {
-					// cycle all options
-					gameParam[tmpPos] = (gameParam[tmpPos] + 1) % tmpLen
-				}
+		_, key := t.waitKey()
+		if key == keyboard.KeyArrowUp && selected > outputLimit[0] {
+			selected--
+		} else if key == keyboard.KeyArrowDown && selected < outputLimit[1] {
+			selected++
+		} else if key == keyboard.KeyArrowRight && !(gameParam[0] != 0 && selected == 1) {
+			// position in gameOptions and gameParam
+			tmpPos := selected - outputLimit[0]
+			// number of scroll options
+			tmpLen := len(gameOptions[tmpPos])
 
-			} else if key == keyboard.KeyArrowLeft && !(gameParam[0] != 0 && selected == 1) {
-				// position in gameOptions and gameParam
-				tmpPos := selected - outputLimit[0]
-				// number of scroll options
-				tmpLen := len(gameOptions[tmpPos])
-				// if there are option
-				if tmpLen > 0 {
-					// cycle all options
-					gameParam[tmpPos] = func() int {
-						newVal := gameParam[tmpPos] - 1
-						if newVal < 0 {
-							return tmpLen + newVal
-						}
-						return newVal
-					}()
-				}
+			// if there are option
+			if tmpLen > 0 {
+				// cycle all options
+				gameParam[tmpPos] = (gameParam[tmpPos] + 1) % tmpLen
+			}
 
-			} else if key == keyboard.KeyEnter {
-				// exit only in cases of Play or Exit
-				switch selected {
-				// Play
-				case 4:
-					return true
-				// Exit
-				case 5:
-					return false
-				}
-			} else {
-				continue
+		} else if key == keyboard.KeyArrowLeft && !(gameParam[0] != 0 && selected == 1) {
+			// position in gameOptions and gameParam
+			tmpPos := selected - outputLimit[0]
+			// number of scroll options
+			tmpLen := len(gameOptions[tmpPos])
+			// if there are option
+			if tmpLen > 0 {
+				// cycle all options
+				gameParam[tmpPos] = func() int {
+					newVal := gameParam[tmpPos] - 1
+					if newVal < 0 {
+						return tmpLen + newVal
+					}
+					return newVal
+				}()
 			}
-			Draw()
+
+		} else if key == keyboard.KeyEnter {
+			// exit only in cases of Play or Exit
+			switch selected {
+			// Play
+			case 4:
+				return gameParam, true
+			// Exit
+			case 5:
+				return gameParam, false
+			}
+		} else {
+			continue
 		}
+		Draw()
 	}
 }
 
-func initGame() bool {
-	initBoard()
+func (t *Terminal) initGame(gameParam [4]int) bool {
+	t.initBoard(gameParam)
 	return true
 }
 
-func initBoard() {
+// convert clock option to seconds, -1 if there is no timer
+func clockSeconds(option int) int {
+	if gameOptions[3][option] == "∞" {
+		return -1
+	}
+	time, _ := strconv.Atoi(strings.Split(gameOptions[3][option], " min")[0])
+	return time * 60
+}
+
+func (t *Terminal) initBoard(gameParam [4]int) {
 	// compute board parameters
 	boardType := gameOptions[0][gameParam[0]]
 	boardSize, _ := strconv.Atoi(strings.Split(gameOptions[1][gameParam[1]], "x")[0])
-	time := -1
-	if gameOptions[3][gameParam[3]] != "∞" {
-		time, _ = strconv.Atoi(strings.Split(gameOptions[3][gameParam[3]], " min")[0])
-		time *= 60
+	time := clockSeconds(gameParam[3])
+	t.board = newBoard(boardType, boardSize, gameParam[2], time, 0)
+}
+
+// create new board of boardType from random seed(0 for a random one), non basic boards are always 9x9
+func newBoard(boardType string, boardSize, difficulty, time int, seed int64) SudokuBoard {
+	// same seed gives the same puzzle unless generation runs out of time
+	if seed == 0 {
+		seed = rand.Int63()
 	}
+	rng := rand.New(rand.NewSource(seed))
 	// choose which board to create
 	switch boardType {
 	case "square":
 		basic := &BasicSudoku{}
-		basic.Init(boardSize, gameParam[2], time)
-		board = basic
+		basic.Init(boardSize, difficulty, time, rng)
+		basic.Seed = seed
+		return basic
 	case "diagonal":
 		diagonal := &DiagonalSudoku{}
-		diagonal.Init(9, gameParam[2], time)
-		board = diagonal
+		diagonal.Init(9, difficulty, time, rng)
+		diagonal.Seed = seed
+		return diagonal
 	case "twodoku":
 		twodoku := &TwoDoku{}
-		twodoku.Init(9, gameParam[2], time)
-		board = twodoku
+		twodoku.Init(9, difficulty, time, rng)
+		twodoku.BoardMain.Seed = seed
+		return twodoku
+	}
+	return nil
+}
+
+// list puzzle files in current directory
+func puzzleFiles() []string {
+	var files []string
+	for _, pattern := range []string{"*.sdk", "*.sdm", "*.txt"} {
+		found, _ := filepath.Glob(pattern)
+		files = append(files, found...)
+	}
+	return files
+}
+
+// choose puzzle file and its options, return true if puzzle was imported
+func (t *Terminal) importMenu() bool {
+	files := puzzleFiles()
+	if len(files) == 0 {
+		t.showError("Could not import the puzzle", errors.New("no .sdk, .sdm or .txt files in the current directory"), "return to menu")
+		return false
+	}
+
+	// start menu options with output
+	outputMenuOptions := [6]string{"File", "Puzzle", "Shape", "Clock", "Play", "Exit"}
+
+	/*initialise menu data*/
+	selected := 4
+	params := [4]int{0, 0, 0, 0}
+	shapes := gameOptions[0][:2]
+
+	// puzzles of the chosen file
+	var puzzles [][][]int
+	var parseErr error
+	parse := func() {
+		puzzles, parseErr = ParsePuzzleFile(files[params[0]])
+		params[1] = 0
+	}
+	parse()
+
+	// number of scroll options in each line
+	optionsLen := func(line int) int {
+		switch line {
+		case 0:
+			return len(files)
+		case 1:
+			return len(puzzles)
+		case 2:
+			return len(shapes)
+		case 3:
+			return len(gameOptions[3])
+		}
+		return 0
+	}
+
+	// function for drawing frame
+	Draw := func() {
+		var frame strings.Builder
+		blueFont.Fprintln(&frame, "Choose puzzle to import!")
+		blueFont.Fprintln(&frame, "(operate with arrows, then press Enter to confirm either Play or Exit)")
+		for index, element := range outputMenuOptions {
+			if selected == index {
+				purpleFont.Fprint(&frame, "> "+element)
+			} else {
+				_, _ = fmt.Fprint(&frame, element)
+			}
+			switch index {
+			case 0:
+				greenFont.Fprint(&frame, " < "+files[params[0]]+" >")
+			case 1:
+				if parseErr != nil {
+					redFont.Fprint(&frame, " "+parseErr.Error())
+				} else {
+					_, _ = greenFont.Fprintf(&frame, " < %d of %d (%dx%d) >", params[1]+1, len(puzzles), len(puzzles[params[1]]), len(puzzles[params[1]]))
+				}
+			case 2:
+				greenFont.Fprint(&frame, " < "+shapes[params[2]]+" >")
+			case 3:
+				greenFont.Fprint(&frame, " < "+gameOptions[3][params[3]]+" >")
+			}
+			_, _ = fmt.Fprintln(&frame)
+		}
+		t.show(frame.String())
+	}
+
+	// draw menu for the first time
+	Draw()
+
+	// iterate until option is chosen
+	for {
+		_, key := t.waitKey()
+		if key == keyboard.KeyArrowUp && selected > 0 {
+			selected--
+		} else if key == keyboard.KeyArrowDown && selected < len(outputMenuOptions)-1 {
+			selected++
+		} else if (key == keyboard.KeyArrowRight || key == keyboard.KeyArrowLeft) && optionsLen(selected) > 0 {
+			// cycle all options
+			tmpLen := optionsLen(selected)
+			if key == keyboard.KeyArrowRight {
+				params[selected] = (params[selected] + 1) % tmpLen
+			} else {
+				params[selected] = (params[selected] - 1 + tmpLen) % tmpLen
+			}
+			// read puzzles of the newly chosen file
+			if selected == 0 {
+				parse()
+			}
+		} else if key == keyboard.KeyEnter {
+			// exit only in cases of Play or Exit
+			switch selected {
+			// Play
+			case 4:
+				if parseErr != nil {
+					t.showError("Could not import the puzzle", parseErr, "choose another puzzle")
+					break
+				}
+				t.show(blueFont.Sprint("Loading..."))
+				imported, err := ImportBoard(puzzles[params[1]], shapes[params[2]], clockSeconds(params[3]))
+				if err != nil {
+					t.showError("Could not import the puzzle", err, "choose another puzzle")
+					break
+				}
+				t.board = imported
+				return true
+			// Exit
+			case 5:
+				return false
+			default:
+				continue
+			}
+		} else {
+			continue
+		}
+		Draw()
+	}
+}
+
+// choose what and how to export, then write it to file
+func (t *Terminal) exportMenu() {
+	// start menu options with output
+	outputMenuOptions := [4]string{"Content", "Format", "Export", "Back"}
+	options := [2][]string{exportContents, exportFormats}
+
+	/*initialise menu data*/
+	selected := 2
+	params := [2]int{ExportProgress, FormatLine}
+
+	// function for drawing frame
+	Draw := func() {
+		var frame strings.Builder
+		blueFont.Fprintln(&frame, "Choose what to export!")
+		blueFont.Fprintln(&frame, "(operate with arrows, then press Enter to confirm either Export or Back)")
+		for index, element := range outputMenuOptions {
+			if selected == index {
+				purpleFont.Fprint(&frame, "> "+element)
+			} else {
+				_, _ = fmt.Fprint(&frame, element)
+			}
+			if index < len(options) {
+				greenFont.Fprint(&frame, " < "+options[index][params[index]]+" >")
+			}
+			_, _ = fmt.Fprintln(&frame)
+		}
+		t.show(frame.String())
+	}
+
+	// draw menu for the first time
+	Draw()
+
+	// iterate until option is chosen
+	for {
+		_, key := t.waitKey()
+		if key == keyboard.KeyArrowUp && selected > 0 {
+			selected--
+		} else if key == keyboard.KeyArrowDown && selected < len(outputMenuOptions)-1 {
+			selected++
+		} else if (key == keyboard.KeyArrowRight || key == keyboard.KeyArrowLeft) && selected < len(options) {
+			// cycle all options
+			tmpLen := len(options[selected])
+			if key == keyboard.KeyArrowRight {
+				params[selected] = (params[selected] + 1) % tmpLen
+			} else {
+				params[selected] = (params[selected] - 1 + tmpLen) % tmpLen
+			}
+		} else if key == keyboard.KeyEnter && selected == 2 {
+			name, err := ExportFile(t.board, params[0], params[1])
+			var frame strings.Builder
+			if err != nil {
+				redFont.Fprintln(&frame, "Could not export the board")
+				_, _ = fmt.Fprintln(&frame, wrapLines(err.Error(), menuWidth))
+			} else {
+				greenFont.Fprintln(&frame, "Exported to "+name)
+				// boards in one line are longer than the screen
+				_, _ = fmt.Fprint(&frame, wrapLines(t.board.Export(params[0], params[1]), menuWidth))
+			}
+			blueFont.Fprintln(&frame, "Press any key to continue")
+			t.show(frame.String())
+			t.waitKey()
+		} else if key == keyboard.KeyEnter && selected == 3 {
+			return
+		} else {
+			continue
+		}
+		Draw()
+	}
+}
+
+// show share codes of the board and wait for any key
+func (t *Terminal) showShareCode() {
+	var frame strings.Builder
+	// codes are read back without spaces and line breaks, so long ones are split in lines
+	blueFont.Fprintln(&frame, "Share code of the puzzle:")
+	_, _ = fmt.Fprintln(&frame, wrapLines(ShareCode(t.board, false), menuWidth))
+	blueFont.Fprintln(&frame, "Share code with your progress and time:")
+	_, _ = fmt.Fprintln(&frame, wrapLines(ShareCode(t.board, true), menuWidth))
+	blueFont.Fprintln(&frame, "Press any key to continue")
+	t.show(frame.String())
+	t.waitKey()
+}
+
+// width of menu lines, so menus fit the 80 columns of the game screen
+const menuWidth = 78
+
+// wrapLines to split every line of text into lines of at most width characters
+func wrapLines(text string, width int) string {
+	var wrapped []string
+	for _, line := range strings.Split(text, "\n") {
+		runes := []rune(line)
+		for len(runes) > width {
+			wrapped = append(wrapped, string(runes[:width]))
+			runes = runes[width:]
+		}
+		wrapped = append(wrapped, string(runes))
 	}
+	return strings.Join(wrapped, "\n")
 }
@@ -0,0 +1,75 @@
+package main
+
+// step to move cursor by one box in direction, wrapping around to the other side of the row or column
+func step(board SudokuBoard, dRow, dCol int) {
+	view := board.View()
+	rows, cols := len(view.Cells), len(view.Cells[0])
+	pos := board.Cursor()
+	// gaps between TwoDoku boards are skipped, the row of the cursor always has a box
+	for {
+		pos = Vector2{(pos.Xpos + dRow + rows) % rows, (pos.Ypos + dCol + cols) % cols}
+		if view.Cells[pos.Xpos][pos.Ypos].Present {
+			board.Place(pos)
+			return
+		}
+	}
+}
+
+// rowEdge to move cursor to the first box of its row, or the last one if last
+func rowEdge(board SudokuBoard, last bool) {
+	view := board.View()
+	pos := board.Cursor()
+	line := view.Cells[pos.Xpos]
+	for j := range line {
+		col := j
+		if last {
+			col = len(line) - 1 - j
+		}
+		if line[col].Present {
+			board.Place(Vector2{pos.Xpos, col})
+			return
+		}
+	}
+}
+
+// nextEmpty to move cursor to the next empty box in reading order, or the previous one if backward
+func nextEmpty(board SudokuBoard, backward bool) {
+	view := board.View()
+	rows, cols := len(view.Cells), len(view.Cells[0])
+	pos := board.Cursor()
+	start := pos.Xpos*cols + pos.Ypos
+	dir := 1
+	if backward {
+		dir = -1
+	}
+	for i := 1; i < rows*cols; i++ {
+		index := ((start+dir*i)%(rows*cols) + rows*cols) % (rows * cols)
+		cell := view.Cells[index/cols][index%cols]
+		if cell.Present && cell.Value == 0 {
+			board.Place(Vector2{index / cols, index % cols})
+			return
+		}
+	}
+}
+
+// jumpNonet to move cursor to the same box of the next nonet in reading order, or the previous one if backward
+func jumpNonet(board SudokuBoard, backward bool) {
+	view := board.View()
+	height, width := view.NonetSize.Xpos, view.NonetSize.Ypos
+	rows, cols := len(view.Cells)/height, len(view.Cells[0])/width
+	pos := board.Cursor()
+	start := (pos.Xpos/height)*cols + pos.Ypos/width
+	dir := 1
+	if backward {
+		dir = -1
+	}
+	// nonets are either whole or missing, so box at the same place in the nonet exists
+	for i := 1; i <= rows*cols; i++ {
+		index := ((start+dir*i)%(rows*cols) + rows*cols) % (rows * cols)
+		target := Vector2{(index/cols)*height + pos.Xpos%height, (index%cols)*width + pos.Ypos%width}
+		if view.Cells[target.Xpos][target.Ypos].Present {
+			board.Place(target)
+			return
+		}
+	}
+}
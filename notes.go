@@ -0,0 +1,117 @@
+package main
+
+// note modes, numbers are entered as values or as pencil marks
+const (
+	NoteOff    = iota // numbers are entered as values
+	NoteCenter        // numbers toggle center marks
+	NoteCorner        // numbers toggle corner marks
+)
+
+// names of note modes shown to the player
+var noteModes = []string{"off", "center", "corner"}
+
+// NoteMarks to store pencil marks of one box, bit n is set when n is marked
+type NoteMarks struct {
+	Center int // candidates written in the middle of the box
+	Corner int // candidates written in the corners of the box
+}
+
+// Empty returns whether there are no marks
+func (n NoteMarks) Empty() bool {
+	return n.Center == 0 && n.Corner == 0
+}
+
+// marked returns numbers with bit set in mask in increasing order
+func marked(mask int) []int {
+	var numbers []int
+	for val := 1; mask>>val != 0; val++ {
+		if mask&(1<<val) != 0 {
+			numbers = append(numbers, val)
+		}
+	}
+	return numbers
+}
+
+// notesAt returns marks of the box at pos
+func (s *BasicSudoku) notesAt(pos Vector2) NoteMarks {
+	// boards created or saved before notes have none
+	if s.Notes == nil {
+		return NoteMarks{}
+	}
+	return s.Notes[pos.Xpos][pos.Ypos]
+}
+
+// setNotes to replace marks of the box at pos
+func (s *BasicSudoku) setNotes(pos Vector2, marks NoteMarks) {
+	if s.Notes == nil {
+		if marks.Empty() {
+			return
+		}
+		s.Notes = make([][]NoteMarks, s.Size)
+		for i := range s.Notes {
+			s.Notes[i] = make([]NoteMarks, s.Size)
+		}
+	}
+	s.Notes[pos.Xpos][pos.Ypos] = marks
+}
+
+// addAction to store new move, dropping moves that were undone
+func (s *BasicSudoku) addAction(change Change) {
+	s.Actions = append(s.Actions[:s.CurrentAction], change)
+	s.CurrentAction = len(s.Actions)
+	s.Changed = true
+	s.showWrong = false
+}
+
+// Note to toggle pencil mark val at current position and return whether marks changed
+func (s *BasicSudoku) Note(val int, corner bool) bool {
+	pos := s.CursorPos
+	// marks are only written in empty boxes
+	if val < 1 || val > s.Size || pos.Xpos == -1 || s.BoardShow[pos.Xpos][pos.Ypos] != 0 {
+		return false
+	}
+	old := s.notesAt(pos)
+	marks := old
+	if corner {
+		marks.Corner ^= 1 << val
+	} else {
+		marks.Center ^= 1 << val
+	}
+	s.addAction(Change{pos, 0, 0, old, marks, false})
+	s.setNotes(pos, marks)
+	return true
+}
+func (s *TwoDoku) Note(val int, corner bool) bool {
+	mainBefore, addBefore := s.BoardMain.CurrentAction, s.BoardAdd.CurrentAction
+	ret := false
+	// marks of the shared nonet are written in both boards
+	if s.BoardMain.CursorPos.Xpos != -1 {
+		ret = s.BoardMain.Note(val, corner)
+	}
+	if s.BoardAdd.CursorPos.Xpos != -1 {
+		ret = s.BoardAdd.Note(val, corner)
+	}
+	s.record(mainBefore, addBefore)
+	return ret
+}
+
+// record to add move for the boards whose current move changed from mainBefore and addBefore
+func (s *TwoDoku) record(mainBefore, addBefore int) {
+	main := s.BoardMain.CurrentAction != mainBefore
+	add := s.BoardAdd.CurrentAction != addBefore
+	if !main && !add {
+		return
+	}
+	// clear next moves
+	s.Actions = s.Actions[:s.CurrentAction]
+	// if main 9-th and additional 1-st nonets - there is 1 move in each board
+	if main && add {
+		s.Actions = append(s.Actions, DoubleChange{true, true})
+		s.Actions = append(s.Actions, DoubleChange{false, true})
+	} else if main {
+		s.Actions = append(s.Actions, DoubleChange{true, false})
+	} else {
+		s.Actions = append(s.Actions, DoubleChange{false, false})
+	}
+	s.CurrentAction = len(s.Actions)
+}
@@ -0,0 +1,57 @@
+package main
+
+import "testing"
+
+// sharedEmpty returns positions of empty boxes of the TwoDoku shared nonet, counted from 0 to 15 like in Place
+func sharedEmpty(t *testing.T, two *TwoDoku, count int) []Vector2 {
+	t.Helper()
+	var empty []Vector2
+	for i := 6; i < 9; i++ {
+		for j := 6; j < 9; j++ {
+			if two.BoardMain.BoardShow[i][j] == 0 && len(empty) < count {
+				empty = append(empty, Vector2{i, j})
+			}
+		}
+	}
+	if len(empty) < count {
+		t.Fatalf("shared nonet has %d empty boxes, want %d", len(empty), count)
+	}
+	return empty
+}
+
+// sharedNotes returns marks of the shared box at pos in the main and the additional board
+func sharedNotes(two *TwoDoku, pos Vector2) (NoteMarks, NoteMarks) {
+	return two.BoardMain.notesAt(pos), two.BoardAdd.notesAt(Vector2{pos.Xpos - 6, pos.Ypos - 6})
+}
+
+func TestTwoDokuSharedNotesUndo(t *testing.T) {
+	two := newBoard("twodoku", 9, 1, -1, 5).(*TwoDoku)
+	pos := sharedEmpty(t, two, 1)[0]
+	two.Place(pos)
+	if !two.Note(4, false) || !two.Note(7, true) {
+		t.Fatal("Note in an empty shared box returned false")
+	}
+
+	// every state after each move, from no marks to both marks
+	states := []NoteMarks{{}, {1 << 4, 0}, {1 << 4, 1 << 7}}
+	check := func(step string, want NoteMarks) {
+		t.Helper()
+		if main, add := sharedNotes(two, pos); main != want || add != want {
+			t.Errorf("%s: marks = %v in main and %v in additional board, want %v", step, main, add, want)
+		}
+	}
+	check("after notes", states[2])
+	for i := len(states) - 2; i >= 0; i-- {
+		two.Undo()
+		check("undo", states[i])
+	}
+	// nothing more to undo
+	two.Undo()
+	check("undo without moves", states[0])
+	for i := 1; i < len(states); i++ {
+		two.Redo()
+		check("redo", states[i])
+	}
+	two.Redo()
+	check("redo without moves", states[2])
+}
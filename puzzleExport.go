@@ -0,0 +1,122 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// what part of the board to export
+const (
+	ExportGivens   = iota // numbers given at the start
+	ExportProgress        // givens and player entries
+	ExportSolution        // full answers board
+)
+
+// format of the export
+const (
+	FormatLine = iota // all boxes in one line
+	FormatGrid        // ascii grid with nonet borders
+	FormatSdk         // SadMan Sudoku .sdk file
+)
+
+// names of export options for menus
+var exportContents = []string{"givens", "givens and entries", "solution"}
+var exportFormats = []string{"one line", "ascii grid", ".sdk file"}
+
+// file names for every export format
+var exportFiles = []string{"export.txt", "export-grid.txt", "export.sdk"}
+
+// symbol to write box value with, blank boxes are dots
+func cellSymbol(val int) byte {
+	if val == 0 {
+		return '.'
+	} else if val > 9 {
+		return byte('A' + val - 10)
+	}
+	return byte('0' + val)
+}
+
+// formatLine to write grid in one line
+func formatLine(grid [][]int) string {
+	var builder strings.Builder
+	for _, line := range grid {
+		for _, element := range line {
+			builder.WriteByte(cellSymbol(element))
+		}
+	}
+	builder.WriteByte('\n')
+	return builder.String()
+}
+
+// formatGrid to write grid with nonet borders
+func (s *BasicSudoku) formatGrid(grid [][]int) string {
+	var builder strings.Builder
+	border := strings.Repeat("+"+strings.Repeat("-", s.NonetSize.Ypos*2+1), s.Size/s.NonetSize.Ypos) + "+\n"
+	for i, line := range grid {
+		if i%s.NonetSize.Xpos == 0 {
+			builder.WriteString(border)
+		}
+		for j, element := range line {
+			if j%s.NonetSize.Ypos == 0 {
+				builder.WriteString("| ")
+			}
+			builder.WriteByte(cellSymbol(element))
+			builder.WriteByte(' ')
+		}
+		builder.WriteString("|\n")
+	}
+	builder.WriteString(border)
+	return builder.String()
+}
+
+// formatSdk to write givens as .sdk puzzle, with board state if it differs
+func formatSdk(givens, state [][]int) string {
+	var builder strings.Builder
+	builder.WriteString("#DExported from SudokuGo\n[Puzzle]\n")
+	for _, line := range givens {
+		builder.WriteString(formatLine([][]int{line}))
+	}
+	if state != nil {
+		builder.WriteString("[State]\n")
+		for _, line := range state {
+			builder.WriteString(formatLine([][]int{line}))
+		}
+	}
+	return builder.String()
+}
+
+// Export returns the board content in chosen format
+func (s *BasicSudoku) Export(content, format int) string {
+	grid := s.BoardShow
+	switch content {
+	case ExportGivens:
+		grid = s.Puzzle()
+	case ExportSolution:
+		grid = s.Board
+	}
+	switch format {
+	case FormatGrid:
+		return s.formatGrid(grid)
+	case FormatSdk:
+		// .sdk stores givens as puzzle and progress as state
+		if content == ExportProgress {
+			return formatSdk(s.Puzzle(), grid)
+		}
+		return formatSdk(grid, nil)
+	}
+	return formatLine(grid)
+}
+func (s *TwoDoku) Export(content, format int) string {
+	// export both boards one after another
+	return s.BoardMain.Export(content, format) + "\n" + s.BoardAdd.Export(content, format)
+}
+
+// ExportFile to write the board content in the file for chosen format, returns file name
+func ExportFile(board SudokuBoard, content, format int) (string, error) {
+	name := exportFiles[format]
+	if err := os.WriteFile(name, []byte(board.Export(content, format)), 0644); err != nil {
+		return "", fmt.Errorf("export %s: %w", name, err)
+	}
+	return name, nil
+}
@@ -0,0 +1,250 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// errors returned when importing puzzles
+var (
+	ErrPuzzleFormat     = errors.New("puzzle format is not recognised")
+	ErrPuzzleInvalid    = errors.New("puzzle givens break the rules")
+	ErrPuzzleUnsolvable = errors.New("puzzle has no solution")
+	ErrPuzzleNotUnique  = errors.New("puzzle has more than one solution")
+)
+
+// board sizes that can be imported
+var importSizes = []int{4, 6, 9, 12}
+
+// parse one symbol of the puzzle, returns -1 if it is not a cell
+func parseCell(char rune) int {
+	switch {
+	case char == '.' || char == '0':
+		return 0
+	case '1' <= char && char <= '9':
+		return int(char - '0')
+	case 'A' <= char && char <= 'C':
+		return int(char - 'A' + 10)
+	case 'a' <= char && char <= 'c':
+		return int(char - 'a' + 10)
+	}
+	return -1
+}
+
+// parse cells of the line, returns nil if line does not contain cells only
+func parseCells(line string) []int {
+	var cells []int
+	for _, char := range line {
+		// skip grid decorations
+		if strings.ContainsRune(" \t|+-", char) {
+			continue
+		}
+		val := parseCell(char)
+		if val == -1 {
+			return nil
+		}
+		cells = append(cells, val)
+	}
+	return cells
+}
+
+// ParsePuzzles to read all puzzles from 81-character lines, .sdk files or collections
+func ParsePuzzles(r io.Reader) ([][][]int, error) {
+	var puzzles [][][]int
+	// rows of the grid being read
+	var rows [][]int
+	// whether current .sdk section contains a puzzle
+	inPuzzle := true
+
+	scanner := bufio.NewScanner(r)
+	lineNum := 0
+	for scanner.Scan() {
+		lineNum++
+		line := strings.TrimSpace(scanner.Text())
+		// skip empty lines and comments(.sdk uses #A, #D and similar for info)
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, "//") {
+			continue
+		}
+		// .sdk sections, only the [Puzzle] one holds givens
+		if strings.HasPrefix(line, "[") {
+			inPuzzle = strings.EqualFold(line, "[Puzzle]")
+			continue
+		}
+		if !inPuzzle {
+			continue
+		}
+
+		// borders of ascii grids
+		if strings.Trim(line, " \t|+-=") == "" {
+			continue
+		}
+		cells := parseCells(line)
+		// collections often put rating or name after the puzzle
+		if cells == nil && len(rows) == 0 {
+			if token := parseCells(strings.Fields(line)[0]); puzzleSize(len(token)) != 0 {
+				cells = token
+			}
+		}
+		// titles between puzzles are skipped(even "001" or "Abc"), but grid must not be interrupted
+		if len(rows) == 0 && puzzleSize(len(cells)) == 0 && !containsInt(importSizes, len(cells)) {
+			continue
+		}
+		if cells == nil {
+			return nil, fmt.Errorf("%w: line %d", ErrPuzzleFormat, lineNum)
+		}
+
+		// whole puzzle in one line
+		if len(rows) == 0 && puzzleSize(len(cells)) != 0 {
+			size := puzzleSize(len(cells))
+			grid := make([][]int, size)
+			for i := range grid {
+				grid[i] = cells[i*size : (i+1)*size]
+			}
+			puzzles = append(puzzles, grid)
+			continue
+		}
+
+		// puzzle written as grid, one row per line
+		if !containsInt(importSizes, len(cells)) || (len(rows) > 0 && len(rows[0]) != len(cells)) {
+			return nil, fmt.Errorf("%w: line %d has %d cells", ErrPuzzleFormat, lineNum, len(cells))
+		}
+		rows = append(rows, cells)
+		if len(rows) == len(cells) {
+			puzzles = append(puzzles, rows)
+			rows = nil
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if len(rows) != 0 {
+		return nil, fmt.Errorf("%w: grid ended after %d rows", ErrPuzzleFormat, len(rows))
+	}
+	if len(puzzles) == 0 {
+		return nil, fmt.Errorf("%w: no puzzles found", ErrPuzzleFormat)
+	}
+
+	// check values fit the board
+	for index, grid := range puzzles {
+		for _, line := range grid {
+			for _, element := range line {
+				if element > len(grid) {
+					return nil, fmt.Errorf("%w: puzzle %d contains %d on %dx%d board", ErrPuzzleFormat, index+1, element, len(grid), len(grid))
+				}
+			}
+		}
+	}
+	return puzzles, nil
+}
+
+// ParsePuzzleFile to read all puzzles from file
+func ParsePuzzleFile(path string) ([][][]int, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return ParsePuzzles(file)
+}
+
+// return board size for number of cells or 0 if it is not supported
+func puzzleSize(cells int) int {
+	for _, size := range importSizes {
+		if size*size == cells {
+			return size
+		}
+	}
+	return 0
+}
+
+// check whether element belong to array
+func containsInt(slice []int, value int) bool {
+	for _, item := range slice {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
+
+// Import to init the board with givens and compute its solution
+func (s *BasicSudoku) Import(grid [][]int, diagonal bool, playTime int) error {
+	s.PreInit(len(grid), playTime)
+	for i := range grid {
+		copy(s.BoardShow[i], grid[i])
+	}
+	s.markGivens(grid)
+	count, solution := solveGrid(grid, s.NonetSize, diagonal, 2)
+	switch count {
+	case -1:
+		return ErrPuzzleInvalid
+	case 0:
+		return ErrPuzzleUnsolvable
+	case 1:
+		s.Board = solution
+		return nil
+	}
+	return ErrPuzzleNotUnique
+}
+
+// ImportBoard to create playable board of boardType("square" or "diagonal") from the grid
+func ImportBoard(grid [][]int, boardType string, playTime int) (SudokuBoard, error) {
+	switch boardType {
+	case "square":
+		basic := &BasicSudoku{}
+		if err := basic.Import(grid, false, playTime); err != nil {
+			return nil, err
+		}
+		return basic, nil
+	case "diagonal":
+		diagonal := &DiagonalSudoku{}
+		if err := diagonal.Import(grid, true, playTime); err != nil {
+			return nil, err
+		}
+		return diagonal, nil
+	}
+	return nil, fmt.Errorf("board type %q can not be imported", boardType)
+}
+
+// Import to init both boards with givens and compute their solutions
+func (s *TwoDoku) Import(gridMain, gridAdd [][]int, playTime int) error {
+	if len(gridMain) != 9 || len(gridAdd) != 9 {
+		return fmt.Errorf("%w: twodoku boards must be 9x9", ErrPuzzleFormat)
+	}
+	// shared nonet must have the same givens on both boards
+	for i := 0; i < 3; i++ {
+		for j := 0; j < 3; j++ {
+			if gridMain[i+6][j+6] != gridAdd[i][j] {
+				return fmt.Errorf("%w: shared nonet differs", ErrPuzzleInvalid)
+			}
+		}
+	}
+	if err := s.BoardMain.Import(gridMain, false, playTime); err != nil {
+		return err
+	}
+	// solve additional board with main board's answers in the shared nonet
+	withShared := make([][]int, 9)
+	for i := range withShared {
+		withShared[i] = append([]int(nil), gridAdd[i]...)
+	}
+	for i := 0; i < 3; i++ {
+		for j := 0; j < 3; j++ {
+			withShared[i][j] = s.BoardMain.Board[i+6][j+6]
+		}
+	}
+	if err := s.BoardAdd.Import(withShared, false, playTime); err != nil {
+		return err
+	}
+	for i := 0; i < 3; i++ {
+		copy(s.BoardAdd.BoardShow[i][:3], gridAdd[i][:3])
+	}
+	s.BoardAdd.markGivens(gridAdd)
+	s.BoardAdd.CursorPos = Vector2{-1, -1} // cursor is -1 -1 if not in scope of the board
+	s.Actions = nil
+	s.CurrentAction = 0
+	return nil
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+// puzzle used in import tests, rows of the one line format
+const importLine = "53..7....6..195....98....6.8...6...34..8.3..17...2...6.6....28....419..5....8..79"
+
+func TestParsePuzzles(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  []string // every puzzle in one line format
+	}{
+		{"one line", importLine, []string{importLine}},
+		{"one line with zeros", strings.ReplaceAll(importLine, ".", "0"), []string{importLine}},
+		{"rating after puzzle", importLine + " 4.5 hard", []string{importLine}},
+		{"collection", "# collection\n" + importLine + "\n\n" + importLine + "\n", []string{importLine, importLine}},
+		{"small boards", "1.3..2..3..4.1..\n..1...........2..3.....4......6.5..2", []string{"1.3..2..3..4.1..", "..1...........2..3.....4......6.5..2"}},
+		{"letters on large board", "A" + strings.Repeat(".", 142) + "c", []string{"A" + strings.Repeat(".", 142) + "C"}},
+		{"ascii grid", `
++-------+-------+-------+
+| 5 3 . | . 7 . | . . . |
+| 6 . . | 1 9 5 | . . . |
+| . 9 8 | . . . | . 6 . |
++-------+-------+-------+
+| 8 . . | . 6 . | . . 3 |
+| 4 . . | 8 . 3 | . . 1 |
+| 7 . . | . 2 . | . . 6 |
++-------+-------+-------+
+| . 6 . | . . . | 2 8 . |
+| . . . | 4 1 9 | . . 5 |
+| . . . | . 8 . | . 7 9 |
++-------+-------+-------+
+`, []string{importLine}},
+		{"sdk file", `#AAuthor
+#DDescription
+[Puzzle]
+53..7....
+6..195...
+.98....6.
+8...6...3
+4..8.3..1
+7...2...6
+.6....28.
+...419..5
+....8..79
+[State]
+534678912
+`, []string{importLine}},
+		{"number as title", "12 easy\n" + importLine, []string{importLine}},
+		{"digits as title", "001\n" + importLine, []string{importLine}},
+		{"letters as title", "Abc\n" + importLine, []string{importLine}},
+		{"title between grids", "Puzzle 1\n1.3.\n..2.\n.3..\n4...\nPuzzle 2\n" + importLine, []string{"1.3...2..3..4...", importLine}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			puzzles, err := ParsePuzzles(strings.NewReader(test.input))
+			if err != nil {
+				t.Fatalf("ParsePuzzles returned %v", err)
+			}
+			var got []string
+			for _, grid := range puzzles {
+				got = append(got, strings.ReplaceAll(strings.TrimSpace(formatLine(grid)), "0", "."))
+			}
+			if strings.Join(got, "\n") != strings.Join(test.want, "\n") {
+				t.Errorf("ParsePuzzles = %q, want %q", got, test.want)
+			}
+		})
+	}
+}
+
+func TestParsePuzzlesErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+	}{
+		{"empty", ""},
+		{"comments only", "# nothing\n// here\n"},
+		{"unsupported length", importLine[:80]},
+		{"grid interrupted", "1.3.\n..2.\nnot a row\n4...\n"},
+		{"rows of different length", "1.3.\n..2.\n.3..\n4....\n"},
+		{"grid ends early", "1.3.\n..2.\n.3..\n"},
+		{"value too large", "1.3..2..3..4.1.9"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			puzzles, err := ParsePuzzles(strings.NewReader(test.input))
+			if !errors.Is(err, ErrPuzzleFormat) {
+				t.Errorf("ParsePuzzles = %v, %v, want ErrPuzzleFormat", puzzles, err)
+			}
+		})
+	}
+}
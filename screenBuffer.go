@@ -0,0 +1,184 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/inancgumus/screen"
+	"io"
+	"strings"
+	"unicode/utf8"
+)
+
+// screenCell is one character on the screen with its ANSI style
+type screenCell struct {
+	char  rune   // character
+	style string // ANSI escape codes to apply before the character
+}
+
+// blank screen cell
+var blankCell = screenCell{' ', ""}
+
+// ScreenBuffer remembers the last frame and redraws only changed characters
+type ScreenBuffer struct {
+	out    io.Writer      // where to draw
+	prev   [][]screenCell // frame that is on the screen now
+	width  int            // screen width when prev was drawn
+	height int            // screen height when prev was drawn
+	valid  bool           // whether prev is still on the screen
+}
+
+// NewScreenBuffer to create screen buffer drawing to out
+func NewScreenBuffer(out io.Writer) *ScreenBuffer {
+	return &ScreenBuffer{out: out}
+}
+
+// Invalidate to repaint the whole screen on the next Draw, used when screen was changed elsewhere
+func (b *ScreenBuffer) Invalidate() {
+	b.valid = false
+}
+
+// parseFrame to split text with ANSI colors into lines of styled characters
+func parseFrame(frame string) [][]screenCell {
+	var lines [][]screenCell
+	var line []screenCell
+	style := ""
+	for i := 0; i < len(frame); {
+		// ANSI escape sequence
+		if frame[i] == '\033' && i+1 < len(frame) && frame[i+1] == '[' {
+			end := strings.IndexAny(frame[i+2:], "ABCDEFGHJKSTfmsu")
+			if end == -1 {
+				break
+			}
+			end += i + 2
+			// only colors are kept, moves and clears would break the frame
+			if frame[end] == 'm' {
+				params := frame[i+2 : end]
+				if params == "" || params == "0" {
+					style = ""
+				} else {
+					style += frame[i : end+1]
+				}
+			}
+			i = end + 1
+			continue
+		}
+		char, size := utf8.DecodeRuneInString(frame[i:])
+		i += size
+		if char == '\n' {
+			lines = append(lines, line)
+			line = nil
+			continue
+		}
+		line = append(line, screenCell{char, style})
+	}
+	if line != nil {
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// size of the frame in characters
+func frameSize(frame [][]screenCell) (int, int) {
+	width := 0
+	for _, line := range frame {
+		width = max(width, len(line))
+	}
+	return width, len(frame)
+}
+
+// layoutFrame to center the frame on the screen, or ask to enlarge the screen if frame does not fit
+func layoutFrame(frame [][]screenCell, width, height int) [][]screenCell {
+	// size is unknown(output is not a terminal)
+	if width <= 0 || height <= 0 {
+		return frame
+	}
+	// last line is left empty so the screen does not scroll
+	height--
+	frameWidth, frameHeight := frameSize(frame)
+	if frameWidth > width || frameHeight > height {
+		message := fmt.Sprintf("Terminal is too small: %dx%d needed, %dx%d now.\nPlease enlarge your terminal.", frameWidth, frameHeight+1, width, height+1)
+		frame = parseFrame(redFont.Sprint(message))
+		frameWidth, frameHeight = frameSize(frame)
+		// even the message does not fit - show as much as possible
+		if frameWidth > width || frameHeight > height {
+			frame = frame[:min(len(frame), height)]
+			for row := range frame {
+				frame[row] = frame[row][:min(len(frame[row]), width)]
+			}
+			return frame
+		}
+	}
+
+	// shift the frame to the center
+	top, left := (height-frameHeight)/2, (width-frameWidth)/2
+	centered := make([][]screenCell, top, top+frameHeight)
+	for _, line := range frame {
+		shifted := make([]screenCell, left, left+len(line))
+		for col := range shifted {
+			shifted[col] = blankCell
+		}
+		centered = append(centered, append(shifted, line...))
+	}
+	return centered
+}
+
+// cell of the frame, blank outside of it
+func frameCell(frame [][]screenCell, row, col int) screenCell {
+	if row >= len(frame) || col >= len(frame[row]) {
+		return blankCell
+	}
+	return frame[row][col]
+}
+
+// Draw to show the frame, writing only characters that differ from the previous frame
+func (b *ScreenBuffer) Draw(frame string) error {
+	width, height := screen.Size()
+	cells := layoutFrame(parseFrame(frame), width, height)
+
+	var out bytes.Buffer
+	// repaint everything if screen was resized or changed by someone else
+	if !b.valid || width != b.width || height != b.height {
+		out.WriteString("\033[0m\033[H\033[2J")
+		b.prev = nil
+		b.width, b.height = width, height
+	}
+
+	style := ""
+	cursor := Vector2{-1, -1}
+	for row := 0; row < max(len(cells), len(b.prev)); row++ {
+		length := 0
+		if row < len(cells) {
+			length = len(cells[row])
+		}
+		if row < len(b.prev) {
+			length = max(length, len(b.prev[row]))
+		}
+		for col := 0; col < length; col++ {
+			cell := frameCell(cells, row, col)
+			if cell == frameCell(b.prev, row, col) {
+				continue
+			}
+			// move only if the cell is not right after the previous one
+			if cursor != (Vector2{row, col}) {
+				_, _ = fmt.Fprintf(&out, "\033[%d;%dH", row+1, col+1)
+			}
+			if cell.style != style {
+				out.WriteString("\033[0m" + cell.style)
+				style = cell.style
+			}
+			out.WriteRune(cell.char)
+			cursor = Vector2{row, col + 1}
+		}
+	}
+	if style != "" {
+		out.WriteString("\033[0m")
+	}
+
+	b.prev = cells
+	b.valid = true
+	if out.Len() == 0 {
+		return nil
+	}
+	_, err := b.out.Write(out.Bytes())
+	return err
+}
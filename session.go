@@ -0,0 +1,105 @@
+package main
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// how often the clock is checked, so shown time and timer end are not late
+const clockTick = 100 * time.Millisecond
+
+// Session is one game on the board, it owns the timer and the render loop
+type Session struct {
+	board   SudokuBoard        // board being played
+	console *ScreenBuffer      // screen the frames are drawn on
+	resized <-chan struct{}    // notified when console window is resized
+	ctx     context.Context    // cancelled when the game is left
+	cancel  context.CancelFunc // stops the goroutines of the session
+	ticks   chan struct{}      // ticks to check the clock and the timer
+	frames  chan string        // frames to draw, empty frame releases the screen
+	wg      sync.WaitGroup     // running goroutines
+}
+
+// newSession to start timer and render loop for the board drawn on console
+func newSession(board SudokuBoard, console *ScreenBuffer, resized <-chan struct{}) *Session {
+	ctx, cancel := context.WithCancel(context.Background())
+	g := &Session{
+		board:   board,
+		console: console,
+		resized: resized,
+		ctx:     ctx,
+		cancel:  cancel,
+		ticks:   make(chan struct{}),
+		frames:  make(chan string),
+	}
+	g.wg.Add(2)
+	go g.tickLoop()
+	go g.renderLoop()
+	return g
+}
+
+// Stop the goroutines and wait until they finish
+func (g *Session) Stop() {
+	g.cancel()
+	g.wg.Wait()
+}
+
+// Draw the frame on the console from the render loop
+func (g *Session) Draw(frame string) {
+	select {
+	case g.frames <- frame:
+	case <-g.ctx.Done():
+	}
+}
+
+// Release the console, so menus can be drawn over the game
+func (g *Session) Release() {
+	g.Draw("")
+}
+
+// timer thread function
+func (g *Session) tickLoop() {
+	defer g.wg.Done()
+	ticker := time.NewTicker(clockTick)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-g.ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		// game loop takes the tick when it is not paused
+		select {
+		case g.ticks <- struct{}{}:
+		case <-g.ctx.Done():
+			return
+		}
+	}
+}
+
+// print thread function
+func (g *Session) renderLoop() {
+	defer g.wg.Done()
+	// last drawn frame
+	frame := ""
+	for {
+		// released console is drawn by menus, they lay out their frames on resize themselves
+		resized := g.resized
+		if frame == "" {
+			resized = nil
+		}
+		select {
+		case <-g.ctx.Done():
+			return
+		case frame = <-g.frames:
+			if frame != "" {
+				_ = g.console.Draw(frame)
+			}
+		case <-resized:
+			// lay out the same frame for the new window size
+			g.console.Invalidate()
+			_ = g.console.Draw(frame)
+		}
+	}
+}
@@ -0,0 +1,263 @@
+package main
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"math/big"
+	"strings"
+)
+
+// ErrShareCode is returned for mistyped or damaged share codes
+var ErrShareCode = errors.New("share code is not valid")
+
+// current version of share codes
+const shareCodeVersion = 1
+
+// alphabet of share codes, without look-alike symbols 0, O, I and l
+const shareAlphabet = "123456789ABCDEFGHJKLMNPQRSTUVWXYZabcdefghijkmnopqrstuvwxyz"
+
+// share code variants
+var shareVariants = []string{"square", "diagonal", "twodoku"}
+
+// flags of share code content
+const (
+	shareProgress = 1 << iota // player entries are included
+	shareTime                 // elapsed and left time are included
+)
+
+// mixedRadix to pack numbers with different ranges into one big number
+type mixedRadix struct {
+	digits []int // packed numbers
+	radix  []int // range of each number
+}
+
+// push number val from 0 to radix-1
+func (m *mixedRadix) push(val, radix int) {
+	m.digits = append(m.digits, val)
+	m.radix = append(m.radix, radix)
+}
+
+// bytes of the packed number, first pushed number is the least significant
+func (m *mixedRadix) bytes() []byte {
+	value := new(big.Int)
+	for i := len(m.digits) - 1; i >= 0; i-- {
+		value.Mul(value, big.NewInt(int64(m.radix[i])))
+		value.Add(value, big.NewInt(int64(m.digits[i])))
+	}
+	return value.Bytes()
+}
+
+// unpacker to read numbers in the same order they were pushed
+type unpacker struct {
+	value *big.Int
+}
+
+// pop next number from 0 to radix-1
+func (u *unpacker) pop(radix int) int {
+	mod := new(big.Int)
+	u.value.DivMod(u.value, big.NewInt(int64(radix)), mod)
+	return int(mod.Int64())
+}
+
+// shareBoards returns variant index and basic boards of the game
+func shareBoards(board SudokuBoard) (int, []*BasicSudoku) {
+	switch b := board.(type) {
+	case *BasicSudoku:
+		return 0, []*BasicSudoku{b}
+	case *DiagonalSudoku:
+		return 1, []*BasicSudoku{&b.BasicSudoku}
+	case *TwoDoku:
+		return 2, []*BasicSudoku{&b.BoardMain, &b.BoardAdd}
+	}
+	return -1, nil
+}
+
+// ShareCode to encode the board in short text, optionally with progress and time
+func ShareCode(board SudokuBoard, progress bool) string {
+	variant, boards := shareBoards(board)
+	if variant == -1 {
+		return ""
+	}
+	size := boards[0].Size
+	flags := 0
+	if progress {
+		flags |= shareProgress | shareTime
+	}
+	data := []byte{shareCodeVersion, byte(variant), byte(size), byte(flags)}
+	if progress {
+		played, left := boards[0].Time.Seconds()
+		data = binary.AppendUvarint(data, uint64(played))
+		// -1 is for no timer
+		data = binary.AppendUvarint(data, uint64(left+1))
+	}
+
+	cells := &mixedRadix{}
+	for _, basic := range boards {
+		givens := basic.Puzzle()
+		for i := 0; i < size; i++ {
+			for j := 0; j < size; j++ {
+				// whether box is given and its value
+				if givens[i][j] == 0 {
+					cells.push(0, 2)
+				} else {
+					cells.push(1, 2)
+					cells.push(givens[i][j]-1, size)
+				}
+			}
+		}
+		if !progress {
+			continue
+		}
+		// player entries of all not given boxes
+		for i := 0; i < size; i++ {
+			for j := 0; j < size; j++ {
+				if givens[i][j] == 0 {
+					cells.push(basic.BoardShow[i][j], size+1)
+				}
+			}
+		}
+	}
+	data = append(data, cells.bytes()...)
+
+	// 2 bytes of checksum to catch typos
+	sum := crc32.ChecksumIEEE(data)
+	data = append(data, byte(sum>>8), byte(sum))
+
+	// encode in share alphabet
+	value := new(big.Int).SetBytes(data)
+	base := big.NewInt(int64(len(shareAlphabet)))
+	mod := new(big.Int)
+	var code []byte
+	for value.Sign() > 0 {
+		value.DivMod(value, base, mod)
+		code = append(code, shareAlphabet[mod.Int64()])
+	}
+	// reverse to put the most significant symbol first
+	for i, j := 0, len(code)-1; i < j; i, j = i+1, j-1 {
+		code[i], code[j] = code[j], code[i]
+	}
+	return string(code)
+}
+
+// FromShareCode to create playable board from the share code
+func FromShareCode(code string) (SudokuBoard, error) {
+	code = strings.Join(strings.Fields(code), "")
+	if code == "" {
+		return nil, ErrShareCode
+	}
+	// decode from share alphabet
+	value := new(big.Int)
+	base := big.NewInt(int64(len(shareAlphabet)))
+	for _, char := range code {
+		index := strings.IndexRune(shareAlphabet, char)
+		if index == -1 {
+			return nil, fmt.Errorf("%w: unexpected symbol %q", ErrShareCode, char)
+		}
+		value.Mul(value, base)
+		value.Add(value, big.NewInt(int64(index)))
+	}
+	data := value.Bytes()
+	if len(data) < 6 {
+		return nil, ErrShareCode
+	}
+	sum := crc32.ChecksumIEEE(data[:len(data)-2])
+	if data[len(data)-2] != byte(sum>>8) || data[len(data)-1] != byte(sum) {
+		return nil, fmt.Errorf("%w: checksum does not match", ErrShareCode)
+	}
+	data = data[:len(data)-2]
+
+	version, variant, size, flags := int(data[0]), int(data[1]), int(data[2]), int(data[3])
+	if version != shareCodeVersion {
+		return nil, fmt.Errorf("%w: version %d is not supported", ErrShareCode, version)
+	}
+	if variant >= len(shareVariants) || !containsInt(importSizes, size) || (variant != 0 && size != 9) {
+		return nil, fmt.Errorf("%w: unknown board", ErrShareCode)
+	}
+	data = data[4:]
+	elapsed, timeLeft := uint64(0), uint64(0)
+	if flags&shareTime != 0 {
+		var n int
+		if elapsed, n = binary.Uvarint(data); n <= 0 {
+			return nil, ErrShareCode
+		}
+		data = data[n:]
+		if timeLeft, n = binary.Uvarint(data); n <= 0 {
+			return nil, ErrShareCode
+		}
+		data = data[n:]
+	}
+
+	// read givens and entries of every board
+	cells := &unpacker{new(big.Int).SetBytes(data)}
+	boardsNum := 1
+	if variant == 2 {
+		boardsNum = 2
+	}
+	givens := make([][][]int, boardsNum)
+	entries := make([][][]int, boardsNum)
+	for index := range givens {
+		givens[index] = make([][]int, size)
+		entries[index] = make([][]int, size)
+		for i := 0; i < size; i++ {
+			givens[index][i] = make([]int, size)
+			entries[index][i] = make([]int, size)
+			for j := 0; j < size; j++ {
+				if cells.pop(2) == 1 {
+					givens[index][i][j] = cells.pop(size) + 1
+				}
+			}
+		}
+		if flags&shareProgress == 0 {
+			continue
+		}
+		for i := 0; i < size; i++ {
+			for j := 0; j < size; j++ {
+				if givens[index][i][j] == 0 {
+					entries[index][i][j] = cells.pop(size + 1)
+				}
+			}
+		}
+	}
+
+	// create the board from givens
+	var board SudokuBoard
+	if variant == 2 {
+		two := &TwoDoku{}
+		if err := two.Import(givens[0], givens[1], -1); err != nil {
+			return nil, err
+		}
+		board = two
+	} else {
+		imported, err := ImportBoard(givens[0], shareVariants[variant], -1)
+		if err != nil {
+			return nil, err
+		}
+		board = imported
+	}
+
+	// progress is written as it was, it is not a move of the player to undo and notes stay as they are
+	_, boards := shareBoards(board)
+	if flags&shareProgress != 0 {
+		for index, basic := range boards {
+			for i := 0; i < size; i++ {
+				for j := 0; j < size; j++ {
+					val := entries[index][i][j]
+					// shared nonet of TwoDoku is the same box in both boards
+					if index == 1 && i < 3 && j < 3 {
+						val = entries[0][i+6][j+6]
+					}
+					if val != 0 {
+						basic.BoardShow[i][j] = val
+					}
+				}
+			}
+			basic.Changed = true
+		}
+	}
+	if flags&shareTime != 0 {
+		boards[0].Time = clockFromSeconds(int(timeLeft)-1, int(elapsed))
+	}
+	return board, nil
+}
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestShareCodeRoundTrip(t *testing.T) {
+	tests := []struct {
+		variant string
+		size    int
+	}{
+		{"square", 4},
+		{"square", 6},
+		{"square", 9},
+		{"square", 12},
+		{"diagonal", 9},
+		{"twodoku", 9},
+	}
+	for _, test := range tests {
+		for _, progress := range []bool{false, true} {
+			t.Run(fmt.Sprintf("%s %d progress %t", test.variant, test.size, progress), func(t *testing.T) {
+				board := newBoard(test.variant, test.size, 1, -1, 7)
+				if progress {
+					// one wrong entry and one revealed answer
+					_, boards := shareBoards(board)
+					pos := firstEmpty(boards[0])
+					board.Place(pos)
+					board.Enter(boards[0].Board[pos.Xpos][pos.Ypos]%test.size + 1)
+					board.RevealRandom()
+				}
+
+				code := ShareCode(board, progress)
+				shared, err := FromShareCode(code)
+				if err != nil {
+					t.Fatalf("FromShareCode(%q) returned %v", code, err)
+				}
+				contents := []int{ExportGivens, ExportSolution}
+				if progress {
+					contents = append(contents, ExportProgress)
+				}
+				for _, content := range contents {
+					if got, want := shared.Export(content, FormatLine), board.Export(content, FormatLine); got != want {
+						t.Errorf("export %s = %q, want %q", exportContents[content], got, want)
+					}
+				}
+				// the game shows long codes split in lines
+				if split, err := FromShareCode(wrapLines(code, 20)); err != nil || ShareCode(split, progress) != code {
+					t.Errorf("FromShareCode of the code split in lines = %v, %v", split, err)
+				}
+				if again := ShareCode(shared, progress); again != code {
+					t.Errorf("code of shared board = %q, want %q", again, code)
+				}
+				// progress from the code is not a move of the player
+				shared.Undo()
+				if got, want := shared.Export(ExportProgress, FormatLine), board.Export(ExportProgress, FormatLine); got != want {
+					t.Errorf("export after undo = %q, want %q", got, want)
+				}
+			})
+		}
+	}
+}
+
+func TestFromShareCodeErrors(t *testing.T) {
+	code := ShareCode(newBoard("square", 9, 0, -1, 7), false)
+	// replacing the last symbol changes the checksum bytes only
+	last := '1'
+	if code[len(code)-1] == '1' {
+		last = '2'
+	}
+	tests := []struct {
+		name string
+		code string
+	}{
+		{"empty", ""},
+		{"spaces", "   "},
+		{"look-alike symbol", "0" + code[1:]},
+		{"too short", code[:4]},
+		{"bad checksum", code[:len(code)-1] + string(last)},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			board, err := FromShareCode(test.code)
+			if !errors.Is(err, ErrShareCode) {
+				t.Errorf("FromShareCode(%q) = %v, %v, want ErrShareCode", test.code, board, err)
+			}
+		})
+	}
+}
+
+// firstEmpty returns position of the first box without a number
+func firstEmpty(board *BasicSudoku) Vector2 {
+	for i, line := range board.BoardShow {
+		for j, element := range line {
+			if element == 0 {
+				return Vector2{i, j}
+			}
+		}
+	}
+	return Vector2{-1, -1}
+}
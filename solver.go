@@ -0,0 +1,215 @@
+package main
+
+import "math/bits"
+
+// solver to count solutions of the grid using candidate bitmasks
+type solver struct {
+	size     int     // size of the board
+	nonet    Vector2 // size of one nonet(width and height)
+	diagonal bool    // whether diagonals must contain all digits too
+	grid     [][]int // board being solved
+	rows     []int   // numbers taken in every row
+	cols     []int   // numbers taken in every column
+	nonets   []int   // numbers taken in every nonet
+	diags    [2]int  // numbers taken in left and right diagonals
+	count    int     // number of solutions found
+	limit    int     // stop after that many solutions
+	solution [][]int // first solution found
+}
+
+// nonet index of the box
+func (s *solver) nonetOf(x, y int) int {
+	return x/s.nonet.Xpos*(s.size/s.nonet.Ypos) + y/s.nonet.Ypos
+}
+
+// toggle number val in all the groups of the box
+func (s *solver) toggle(x, y, val int) {
+	bit := 1 << val
+	s.rows[x] ^= bit
+	s.cols[y] ^= bit
+	s.nonets[s.nonetOf(x, y)] ^= bit
+	if s.diagonal && x == y {
+		s.diags[0] ^= bit
+	}
+	if s.diagonal && x == s.size-y-1 {
+		s.diags[1] ^= bit
+	}
+}
+
+// numbers available for the box as bitmask
+func (s *solver) candidates(x, y int) int {
+	taken := s.rows[x] | s.cols[y] | s.nonets[s.nonetOf(x, y)]
+	if s.diagonal && x == y {
+		taken |= s.diags[0]
+	}
+	if s.diagonal && x == s.size-y-1 {
+		taken |= s.diags[1]
+	}
+	// bits 1 to size
+	return ^taken & (1<<(s.size+1) - 2)
+}
+
+// search to fill the box with the least candidates first
+func (s *solver) search() {
+	bestX, bestY, bestMask, bestCount := -1, -1, 0, s.size+1
+	for i := 0; i < s.size && bestCount > 1; i++ {
+		for j := 0; j < s.size; j++ {
+			if s.grid[i][j] != 0 {
+				continue
+			}
+			mask := s.candidates(i, j)
+			count := bits.OnesCount(uint(mask))
+			if count < bestCount {
+				bestX, bestY, bestMask, bestCount = i, j, mask, count
+				if count <= 1 {
+					break
+				}
+			}
+		}
+	}
+	// no empty boxes left - solution found
+	if bestX == -1 {
+		s.count++
+		if s.solution == nil {
+			s.solution = make([][]int, s.size)
+			for i := range s.grid {
+				s.solution[i] = append([]int(nil), s.grid[i]...)
+			}
+		}
+		return
+	}
+	for mask := bestMask; mask != 0 && s.count < s.limit; mask &= mask - 1 {
+		val := bits.TrailingZeros(uint(mask))
+		s.grid[bestX][bestY] = val
+		s.toggle(bestX, bestY, val)
+		s.search()
+		s.toggle(bestX, bestY, val)
+	}
+	s.grid[bestX][bestY] = 0
+}
+
+// newSolver to prepare solver for the grid, false if givens already break the rules
+func newSolver(grid [][]int, nonet Vector2, diagonal bool) (*solver, bool) {
+	size := len(grid)
+	s := &solver{
+		size:     size,
+		nonet:    nonet,
+		diagonal: diagonal,
+		grid:     make([][]int, size),
+		rows:     make([]int, size),
+		cols:     make([]int, size),
+		nonets:   make([]int, size),
+	}
+	for i := range grid {
+		s.grid[i] = append([]int(nil), grid[i]...)
+		for j, val := range grid[i] {
+			if val == 0 {
+				continue
+			}
+			// number is already taken by one of the groups
+			if s.candidates(i, j)&(1<<val) == 0 {
+				return nil, false
+			}
+			s.toggle(i, j, val)
+		}
+	}
+	return s, true
+}
+
+// solveGrid returns up to limit solutions count and the first solution,
+// count is -1 if givens already break the rules
+func solveGrid(grid [][]int, nonet Vector2, diagonal bool, limit int) (int, [][]int) {
+	s, ok := newSolver(grid, nonet, diagonal)
+	if !ok {
+		return -1, nil
+	}
+	s.limit = limit
+	s.search()
+	return s.count, s.solution
+}
+
+// groups returns boxes of every row, column, nonet and diagonal
+func (s *solver) groups() [][]Vector2 {
+	groups := make([][]Vector2, 3*s.size, 3*s.size+2)
+	for i := 0; i < s.size; i++ {
+		for j := 0; j < s.size; j++ {
+			groups[i] = append(groups[i], Vector2{i, j})
+			groups[s.size+j] = append(groups[s.size+j], Vector2{i, j})
+			groups[2*s.size+s.nonetOf(i, j)] = append(groups[2*s.size+s.nonetOf(i, j)], Vector2{i, j})
+		}
+	}
+	if s.diagonal {
+		left, right := []Vector2{}, []Vector2{}
+		for i := 0; i < s.size; i++ {
+			left = append(left, Vector2{i, i})
+			right = append(right, Vector2{i, s.size - i - 1})
+		}
+		groups = append(groups, left, right)
+	}
+	return groups
+}
+
+// place number val in the box
+func (s *solver) place(x, y, val int) {
+	s.grid[x][y] = val
+	s.toggle(x, y, val)
+}
+
+// gradeGrid returns difficulty of the puzzle the way a person would solve it:
+// 0(easy) if naked singles are enough, 1(medium) if hidden singles are needed,
+// 2(hard) if other techniques are required and -1 if givens break the rules
+func gradeGrid(grid [][]int, nonet Vector2, diagonal bool) int {
+	s, ok := newSolver(grid, nonet, diagonal)
+	if !ok {
+		return -1
+	}
+	groups := s.groups()
+	grade := 0
+	for {
+		placed, empty := false, false
+		// naked singles - box with the only candidate
+		for i := 0; i < s.size; i++ {
+			for j := 0; j < s.size; j++ {
+				if s.grid[i][j] != 0 {
+					continue
+				}
+				empty = true
+				mask := s.candidates(i, j)
+				if bits.OnesCount(uint(mask)) == 1 {
+					s.place(i, j, bits.TrailingZeros(uint(mask)))
+					placed = true
+				}
+			}
+		}
+		if !empty {
+			return grade
+		}
+		if placed {
+			continue
+		}
+		// hidden singles - number with the only box in the group
+		for _, group := range groups {
+			for val := 1; val <= s.size; val++ {
+				count, last := 0, Vector2{}
+				for _, pos := range group {
+					if s.grid[pos.Xpos][pos.Ypos] == val {
+						count = -1
+						break
+					}
+					if s.grid[pos.Xpos][pos.Ypos] == 0 && s.candidates(pos.Xpos, pos.Ypos)&(1<<val) != 0 {
+						count++
+						last = pos
+					}
+				}
+				if count == 1 {
+					s.place(last.Xpos, last.Ypos, val)
+					placed = true
+				}
+			}
+		}
+		if !placed {
+			return 2
+		}
+		grade = 1
+	}
+}
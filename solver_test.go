@@ -0,0 +1,84 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+// solution of importLine
+const importSolution = "534678912672195348198342567859761423426853791713924856961537284287419635345286179"
+
+// puzzle that needs more than singles
+const hardLine = "8..........36......7..9.2...5...7.......457.....1...3...1....68..85...1..9....4.."
+
+// parseLine to read puzzle in one line format for tests
+func parseLine(t *testing.T, line string) [][]int {
+	t.Helper()
+	puzzles, err := ParsePuzzles(strings.NewReader(line))
+	if err != nil || len(puzzles) != 1 {
+		t.Fatalf("ParsePuzzles(%q) = %v, %v", line, puzzles, err)
+	}
+	return puzzles[0]
+}
+
+func TestSolveGrid(t *testing.T) {
+	tests := []struct {
+		name     string
+		line     string
+		diagonal bool
+		limit    int
+		count    int
+		solution string // expected solution when count is 1
+	}{
+		{"unique", importLine, false, 2, 1, importSolution},
+		{"hard", hardLine, false, 2, 1, "812753649943682175675491283154237896369845721287169534521974368438526917796318452"},
+		{"solved already", importSolution, false, 2, 1, importSolution},
+		{"empty small board", strings.Repeat(".", 16), false, 2, 2, ""},
+		{"limit stops search", strings.Repeat(".", 36), false, 5, 5, ""},
+		{"no solution", "12....3....4....", false, 2, 0, ""},
+		{"repeated given", "55" + importLine[2:], false, 2, -1, ""},
+		{"broken diagonal", importSolution, true, 2, -1, ""},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			grid := parseLine(t, test.line)
+			puzzle := formatLine(grid)
+			count, solution := solveGrid(grid, nonetSize(len(grid)), test.diagonal, test.limit)
+			if count != test.count {
+				t.Fatalf("solveGrid count = %d, want %d", count, test.count)
+			}
+			if test.solution != "" {
+				if got := strings.TrimSpace(formatLine(solution)); got != test.solution {
+					t.Errorf("solveGrid solution = %s, want %s", got, test.solution)
+				}
+			}
+			if got := formatLine(grid); got != puzzle {
+				t.Errorf("solveGrid changed the puzzle to %s", got)
+			}
+		})
+	}
+}
+
+func TestGradeGrid(t *testing.T) {
+	tests := []struct {
+		name     string
+		line     string
+		diagonal bool
+		grade    int
+	}{
+		{"one box missing", "." + importSolution[1:], false, 0},
+		{"naked singles", importLine, false, 0},
+		{"hidden singles", "41.....6.5.83....99....8...3...597......6.5.....7.2....26.3.....4......2753.8....", false, 1},
+		{"other techniques", hardLine, false, 2},
+		{"repeated given", "55" + importLine[2:], false, -1},
+		{"broken diagonal", importSolution, true, -1},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			grid := parseLine(t, test.line)
+			if grade := gradeGrid(grid, nonetSize(len(grid)), test.diagonal); grade != test.grade {
+				t.Errorf("gradeGrid = %d, want %d", grade, test.grade)
+			}
+		})
+	}
+}